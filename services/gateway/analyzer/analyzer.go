@@ -0,0 +1,110 @@
+// Package analyzer runs the configured code-analysis stages (SonarQube,
+// golangci-lint, eslint, semgrep, and the legacy regex heuristics) over a
+// commit diff in parallel and normalizes their findings into one
+// ReviewIssue schema, optionally filtered down to lines the diff actually
+// touched (the reviewdog technique).
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Severity mirrors the severities the VS Code extension already renders.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue is the common schema every Stage normalizes its findings into,
+// keyed to {File, Line, RuleID, Severity} as callers expect.
+type Issue struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Source   string   `json:"source"` // which stage produced this, e.g. "sonarqube", "golangci-lint"
+}
+
+// Request is the input every Stage receives: the raw unified diff plus
+// the file list and full diff text the legacy heuristics look at.
+type Request struct {
+	Repo      string
+	CommitSHA string
+	Diff      string
+	Files     []string
+}
+
+// Stage is one pluggable analyzer. Implementations should respect ctx
+// cancellation so a slow external scanner can't block the whole pipeline
+// past its deadline.
+type Stage interface {
+	Name() string
+	Run(ctx context.Context, req Request) ([]Issue, error)
+}
+
+// StageResult records whether a stage succeeded, so a single scanner
+// outage degrades the response instead of failing the whole review.
+type StageResult struct {
+	Stage  string  `json:"stage"`
+	Issues []Issue `json:"-"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Pipeline runs a fixed set of Stages concurrently and merges their
+// output.
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline builds a Pipeline from the given stages, skipping any nil
+// entries so callers can conditionally include a stage (e.g. Sonar only
+// when SonarServiceURL is configured) without branching on slice length.
+func NewPipeline(stages ...Stage) *Pipeline {
+	nonNil := make([]Stage, 0, len(stages))
+	for _, s := range stages {
+		if s != nil {
+			nonNil = append(nonNil, s)
+		}
+	}
+	return &Pipeline{stages: nonNil}
+}
+
+// Run executes every stage in parallel and returns their combined,
+// deterministically-ordered issues alongside a per-stage status report.
+func (p *Pipeline) Run(ctx context.Context, req Request) ([]Issue, []StageResult) {
+	results := make([]StageResult, len(p.stages))
+	var wg sync.WaitGroup
+	wg.Add(len(p.stages))
+
+	for i, stage := range p.stages {
+		i, stage := i, stage
+		go func() {
+			defer wg.Done()
+			issues, err := stage.Run(ctx, req)
+			result := StageResult{Stage: stage.Name(), Issues: issues}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	var all []Issue
+	for _, result := range results {
+		all = append(all, result.Issues...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].File != all[j].File {
+			return all[i].File < all[j].File
+		}
+		return all[i].Line < all[j].Line
+	})
+	return all, results
+}
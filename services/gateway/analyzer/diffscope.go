@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// addedRange is one contiguous run of added lines in the new file, as
+// described by a single "@@ -a,b +c,d @@" hunk header.
+type addedRange struct {
+	start, end int // inclusive, 1-indexed, in the new file
+}
+
+// hunkHeaderRe matches unified diff hunk headers. The old-side count and
+// new-side count are both optional (a lone number means count=1).
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// fileHeaderRe matches "+++ b/path/to/file" lines, which name the file a
+// following run of hunks belongs to.
+var fileHeaderRe = regexp.MustCompile(`^\+\+\+ (?:b/)?(.+)$`)
+
+// DiffScope maps a unified diff to the set of lines each touched file
+// added, so Filter can drop any finding whose line isn't one of them.
+type DiffScope struct {
+	added map[string][]addedRange
+}
+
+// ParseDiffScope walks a unified diff's "@@ -a,b +c,d @@" hunk headers and
+// records, per file, which new-file line numbers were added. This is the
+// same technique reviewdog uses to scope lint output to a diff.
+func ParseDiffScope(diff string) *DiffScope {
+	scope := &DiffScope{added: make(map[string][]addedRange)}
+
+	var currentFile string
+	var newLine int
+	inHunk := false
+
+	for _, line := range strings.Split(diff, "\n") {
+		if m := fileHeaderRe.FindStringSubmatch(line); m != nil {
+			currentFile = m[1]
+			inHunk = false
+			continue
+		}
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			newLine, _ = strconv.Atoi(m[1])
+			inHunk = true
+			continue
+		}
+		if !inHunk || currentFile == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			scope.added[currentFile] = appendAddedLine(scope.added[currentFile], newLine)
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			// Removed line: doesn't exist in the new file, new-line
+			// counter doesn't advance.
+		default:
+			newLine++
+		}
+	}
+	return scope
+}
+
+// appendAddedLine extends the last range if line is contiguous with it,
+// otherwise starts a new one.
+func appendAddedLine(ranges []addedRange, line int) []addedRange {
+	if n := len(ranges); n > 0 && ranges[n-1].end == line-1 {
+		ranges[n-1].end = line
+		return ranges
+	}
+	return append(ranges, addedRange{start: line, end: line})
+}
+
+// Contains reports whether line was an added line of file in the diff.
+func (s *DiffScope) Contains(file string, line int) bool {
+	for _, r := range s.added[matchFile(s.added, file)] {
+		if line >= r.start && line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFile tolerates findings that report an absolute or a/-prefixed
+// path by falling back to a suffix match against the diff's recorded
+// file names.
+func matchFile(added map[string][]addedRange, file string) string {
+	if _, ok := added[file]; ok {
+		return file
+	}
+	for known := range added {
+		if strings.HasSuffix(file, known) || strings.HasSuffix(known, file) {
+			return known
+		}
+	}
+	return file
+}
+
+// Filter keeps only the issues whose line was added by the diff.
+func Filter(issues []Issue, scope *DiffScope) []Issue {
+	out := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if scope.Contains(issue.File, issue.Line) {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
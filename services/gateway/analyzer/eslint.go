@@ -0,0 +1,56 @@
+package analyzer
+
+import "encoding/json"
+
+// eslintResult mirrors one entry of `eslint --format json` output.
+type eslintResult struct {
+	FilePath string `json:"filePath"`
+	Messages []struct {
+		RuleID   string `json:"ruleId"`
+		Severity int    `json:"severity"` // 1 = warning, 2 = error
+		Message  string `json:"message"`
+		Line     int    `json:"line"`
+	} `json:"messages"`
+}
+
+// NewESLintStage runs `eslint --format json` against workDir.
+func NewESLintStage(workDir string) Stage {
+	return &ExecStage{
+		StageName: "eslint",
+		Command:   "eslint",
+		Args:      []string{".", "--format", "json"},
+		WorkDir:   workDir,
+		Parse:     parseESLintOutput,
+	}
+}
+
+func parseESLintOutput(stdout []byte) ([]Issue, error) {
+	var results []eslintResult
+	if len(stdout) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(stdout, &results); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, result := range results {
+		for _, msg := range result.Messages {
+			issues = append(issues, Issue{
+				File:     result.FilePath,
+				Line:     msg.Line,
+				RuleID:   msg.RuleID,
+				Severity: normalizeESLintSeverity(msg.Severity),
+				Message:  msg.Message,
+			})
+		}
+	}
+	return issues, nil
+}
+
+func normalizeESLintSeverity(severity int) Severity {
+	if severity >= 2 {
+		return SeverityError
+	}
+	return SeverityWarning
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// ExecStage shells out to a locally installed linter (golangci-lint,
+// eslint, semgrep, ...) configured to emit JSON, and adapts that JSON into
+// []Issue via Parse. The diff is piped to the command's stdin so stages
+// that can reason about a standalone diff (semgrep --config with
+// --json-stats, eslint --stdin) don't need a full repo checkout; stages
+// that do need one should set WorkDir to a shared clone.
+type ExecStage struct {
+	StageName string
+	Command   string
+	Args      []string
+	WorkDir   string
+	Parse     func(stdout []byte) ([]Issue, error)
+}
+
+func (s *ExecStage) Name() string { return s.StageName }
+
+func (s *ExecStage) Run(ctx context.Context, req Request) ([]Issue, error) {
+	cmd := exec.CommandContext(ctx, s.Command, s.Args...)
+	cmd.Dir = s.WorkDir
+	cmd.Stdin = strings.NewReader(req.Diff)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// golangci-lint, eslint, and semgrep all exit non-zero when they find
+	// issues, so a non-nil err here doesn't necessarily mean the run
+	// failed — only a missing/unparsable stdout does.
+	_ = cmd.Run()
+
+	issues, err := s.Parse(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		issues[i].Source = s.StageName
+	}
+	return issues, nil
+}
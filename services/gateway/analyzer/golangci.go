@@ -0,0 +1,62 @@
+package analyzer
+
+import "encoding/json"
+
+// golangciLintReport mirrors the subset of `golangci-lint run
+// --out-format json` this stage cares about.
+type golangciLintReport struct {
+	Issues []struct {
+		FromLinter string `json:"FromLinter"`
+		Text       string `json:"Text"`
+		Severity   string `json:"Severity"`
+		Pos        struct {
+			Filename string `json:"Filename"`
+			Line     int    `json:"Line"`
+		} `json:"Pos"`
+	} `json:"Issues"`
+}
+
+// NewGolangciLintStage runs `golangci-lint run --out-format json` against
+// workDir (a checked-out copy of the repo at the commit being reviewed).
+func NewGolangciLintStage(workDir string) Stage {
+	return &ExecStage{
+		StageName: "golangci-lint",
+		Command:   "golangci-lint",
+		Args:      []string{"run", "--out-format", "json"},
+		WorkDir:   workDir,
+		Parse:     parseGolangciLintOutput,
+	}
+}
+
+func parseGolangciLintOutput(stdout []byte) ([]Issue, error) {
+	var report golangciLintReport
+	if len(stdout) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(stdout, &report); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(report.Issues))
+	for _, raw := range report.Issues {
+		issues = append(issues, Issue{
+			File:     raw.Pos.Filename,
+			Line:     raw.Pos.Line,
+			RuleID:   raw.FromLinter,
+			Severity: normalizeGolangciSeverity(raw.Severity),
+			Message:  raw.Text,
+		})
+	}
+	return issues, nil
+}
+
+func normalizeGolangciSeverity(s string) Severity {
+	switch s {
+	case "error":
+		return SeverityError
+	case "warning":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
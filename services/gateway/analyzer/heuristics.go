@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// HeuristicsStage is the original regex-based scan, kept as the always-on
+// fallback stage so a review still returns something useful when none of
+// the external scanners are configured.
+type HeuristicsStage struct{}
+
+func (HeuristicsStage) Name() string { return "heuristics" }
+
+var todoRe = regexp.MustCompile(`(?i)(TODO|FIXME|XXX|HACK)`)
+
+func (HeuristicsStage) Run(_ context.Context, req Request) ([]Issue, error) {
+	var issues []Issue
+	diff := req.Diff
+
+	if todoRe.MatchString(diff) {
+		issues = append(issues, Issue{Severity: SeverityWarning, RuleID: "heuristic.todo", Message: "Found TODO/FIXME comment that should be addressed", Source: "heuristics"})
+	}
+	if strings.Contains(diff, "console.log") {
+		issues = append(issues, Issue{Severity: SeverityInfo, RuleID: "heuristic.console-log", Message: "console.log statement should be removed before production", Source: "heuristics"})
+	}
+	if strings.Contains(diff, ".go") || hasGoFiles(req.Files) {
+		if strings.Contains(diff, "err :=") && !strings.Contains(diff, "if err != nil") {
+			issues = append(issues, Issue{Severity: SeverityError, RuleID: "heuristic.unhandled-error", Message: "Potential unhandled error in Go code", Source: "heuristics"})
+		}
+	}
+	if strings.Contains(diff, "async") && !strings.Contains(diff, "try") {
+		issues = append(issues, Issue{Severity: SeverityWarning, RuleID: "heuristic.async-no-catch", Message: "Async function without try/catch error handling", Source: "heuristics"})
+	}
+
+	return issues, nil
+}
+
+func hasGoFiles(files []string) bool {
+	for _, f := range files {
+		if strings.HasSuffix(f, ".go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+import "encoding/json"
+
+// semgrepReport mirrors the subset of `semgrep --json` output this stage
+// normalizes.
+type semgrepReport struct {
+	Results []struct {
+		CheckID string `json:"check_id"`
+		Path    string `json:"path"`
+		Start   struct {
+			Line int `json:"line"`
+		} `json:"start"`
+		Extra struct {
+			Message  string `json:"message"`
+			Severity string `json:"severity"`
+		} `json:"extra"`
+	} `json:"results"`
+}
+
+// NewSemgrepStage runs `semgrep --config auto --json` against workDir.
+func NewSemgrepStage(workDir string) Stage {
+	return &ExecStage{
+		StageName: "semgrep",
+		Command:   "semgrep",
+		Args:      []string{"--config", "auto", "--json", "--quiet"},
+		WorkDir:   workDir,
+		Parse:     parseSemgrepOutput,
+	}
+}
+
+func parseSemgrepOutput(stdout []byte) ([]Issue, error) {
+	var report semgrepReport
+	if len(stdout) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(stdout, &report); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(report.Results))
+	for _, raw := range report.Results {
+		issues = append(issues, Issue{
+			File:     raw.Path,
+			Line:     raw.Start.Line,
+			RuleID:   raw.CheckID,
+			Severity: normalizeSemgrepSeverity(raw.Extra.Severity),
+			Message:  raw.Extra.Message,
+		})
+	}
+	return issues, nil
+}
+
+func normalizeSemgrepSeverity(s string) Severity {
+	switch s {
+	case "ERROR":
+		return SeverityError
+	case "WARNING":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SonarStage submits a diff to an external SonarQube scanner service
+// (fronted by SonarServiceURL) and normalizes its findings.
+type SonarStage struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewSonarStage builds a SonarStage pointed at baseURL (app.cfg.SonarServiceURL).
+func NewSonarStage(baseURL string) Stage {
+	return &SonarStage{BaseURL: strings.TrimRight(baseURL, "/"), Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (s *SonarStage) Name() string { return "sonarqube" }
+
+type sonarScanRequest struct {
+	Repo      string `json:"repo"`
+	CommitSHA string `json:"commit_sha"`
+	Diff      string `json:"diff"`
+}
+
+type sonarScanResponse struct {
+	Issues []struct {
+		Component string `json:"component"`
+		Line      int    `json:"line"`
+		Rule      string `json:"rule"`
+		Severity  string `json:"severity"`
+		Message   string `json:"message"`
+	} `json:"issues"`
+}
+
+func (s *SonarStage) Run(ctx context.Context, req Request) ([]Issue, error) {
+	body, err := json.Marshal(sonarScanRequest{Repo: req.Repo, CommitSHA: req.CommitSHA, Diff: req.Diff})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/api/v1/scan", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sonar service returned status %d", resp.StatusCode)
+	}
+
+	var parsed sonarScanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(parsed.Issues))
+	for _, raw := range parsed.Issues {
+		issues = append(issues, Issue{
+			File:     raw.Component,
+			Line:     raw.Line,
+			RuleID:   raw.Rule,
+			Severity: normalizeSonarSeverity(raw.Severity),
+			Message:  raw.Message,
+		})
+	}
+	return issues, nil
+}
+
+func normalizeSonarSeverity(s string) Severity {
+	switch strings.ToUpper(s) {
+	case "BLOCKER", "CRITICAL":
+		return SeverityError
+	case "MAJOR", "MINOR":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v61/github"
+)
+
+// handleGitHubAppCallback is the App's configured Setup URL: GitHub sends
+// the user here with installation_id and setup_action once they've picked
+// repos on the install page handleGitHubInstallStart redirected them to.
+// We fetch the installation's account/permissions using the App's own JWT
+// and persist it so webhook handling and Check Run publishing can look it
+// up by installation ID.
+func (a *App) handleGitHubAppCallback(w http.ResponseWriter, r *http.Request) {
+	installationID, err := parseInt64(r.URL.Query().Get("installation_id"))
+	if err != nil {
+		http.Error(w, "missing or invalid installation_id", http.StatusBadRequest)
+		return
+	}
+
+	source, err := getRemoteSourceBySlug(a.db, "github")
+	if err != nil {
+		http.Error(w, "github remote source not registered", http.StatusInternalServerError)
+		return
+	}
+
+	client, err := a.appClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	install, _, err := client.Apps.GetInstallation(r.Context(), installationID)
+	if err != nil {
+		http.Error(w, "failed to fetch installation: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	permissions, err := json.Marshal(install.GetPermissions())
+	if err != nil {
+		permissions = json.RawMessage("{}")
+	}
+
+	if err := upsertInstallation(a.db, Installation{
+		ID:             install.GetID(),
+		RemoteSourceID: source.ID,
+		AccountLogin:   install.GetAccount().GetLogin(),
+		AccountID:      install.GetAccount().GetID(),
+		Permissions:    permissions,
+		Events:         install.Events,
+	}); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, requestOrigin(r)+"/auth/workspace?installed=1", http.StatusFound)
+}
+
+// appClient authenticates as the GitHub App itself (not a specific
+// installation), for installation-management endpoints like
+// GetInstallation.
+func (a *App) appClient() (*github.Client, error) {
+	key := []byte(strings.ReplaceAll(a.cfg.GitHubAppPrivateKey, "\\n", "\n"))
+	tr, err := ghinstallation.NewAppsTransport(http.DefaultTransport, a.cfg.GitHubAppID, key)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewClient(&http.Client{Transport: tr}), nil
+}
+
+// installationClient authenticates as a specific installation, scoped to
+// only the repos/permissions that installation was granted, for posting
+// Check Runs and reading commit diffs during webhook handling.
+func (a *App) installationClient(ctx context.Context, installationID int64) (*github.Client, error) {
+	key := []byte(strings.ReplaceAll(a.cfg.GitHubAppPrivateKey, "\\n", "\n"))
+	tr, err := ghinstallation.New(http.DefaultTransport, a.cfg.GitHubAppID, installationID, key)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewClient(&http.Client{Transport: tr}), nil
+}
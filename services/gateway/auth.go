@@ -13,96 +13,119 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/go-github/v61/github"
 	"golang.org/x/oauth2"
-	githuboauth "golang.org/x/oauth2/github"
+
+	"git-app-gateway/gitsource"
 )
 
-func (a *App) handleGitHubLogin(w http.ResponseWriter, r *http.Request) {
+// handleLogin starts the OAuth dance for the remote source named in the
+// "source" path value (e.g. /auth/github/login, /auth/gitlab/login). It
+// replaces the old GitHub-only handleGitHubLogin now that multiple
+// providers can be registered in remote_sources.
+func (a *App) handleLogin(w http.ResponseWriter, r *http.Request) {
+	source, err := a.remoteSourceFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	state, err := randomState()
 	if err != nil {
 		http.Error(w, "state error", http.StatusInternalServerError)
 		return
 	}
-	redirectURL := a.oauthConfig().AuthCodeURL(state, oauth2.AccessTypeOnline)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		HttpOnly: true,
-		Secure:   isHTTPSRequest(r),
-		SameSite: http.SameSiteLaxMode,
-		Path:     "/",
-		MaxAge:   300,
-	})
+
+	setOAuthCookies(w, r, state, source.ID)
+
+	redirectURL := provider.OAuthConfig().AuthCodeURL(state, oauth2.AccessTypeOnline)
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
 
-func (a *App) handleGitHubCallback(w http.ResponseWriter, r *http.Request) {
+// handleCallback completes the OAuth dance started by handleLogin. The
+// remote source is recovered from the oauth_source_id cookie set at login
+// time, not from the URL, so the redirect URI registered with each forge
+// can stay a single fixed path.
+func (a *App) handleCallback(w http.ResponseWriter, r *http.Request) {
 	state := r.URL.Query().Get("state")
 	code := r.URL.Query().Get("code")
 
-	// Validate state parameter
 	if state == "" {
 		http.Error(w, "missing state parameter", http.StatusBadRequest)
 		return
 	}
-
-	// Validate authorization code
 	if code == "" {
 		http.Error(w, "missing authorization code", http.StatusBadRequest)
 		return
 	}
 
-	// Verify state matches stored cookie
 	stored, err := r.Cookie("oauth_state")
 	if err != nil {
 		http.Error(w, "state cookie not found - possible CSRF attack", http.StatusUnauthorized)
 		return
 	}
-
 	if stored.Value != state {
 		http.Error(w, "state mismatch - possible CSRF attack", http.StatusUnauthorized)
 		return
 	}
 
-	// Exchange code for access token
-	token, err := a.oauthConfig().Exchange(context.Background(), code)
+	sourceCookie, err := r.Cookie("oauth_source_id")
 	if err != nil {
-		http.Error(w, "failed to exchange authorization code: "+err.Error(), http.StatusUnauthorized)
+		http.Error(w, "missing oauth source cookie", http.StatusBadRequest)
 		return
 	}
-	if strings.TrimSpace(token.AccessToken) == "" {
-		http.Error(w, "oauth exchange returned empty access token", http.StatusUnauthorized)
+	sourceID, err := parseInt64(sourceCookie.Value)
+	if err != nil {
+		http.Error(w, "invalid oauth source cookie", http.StatusBadRequest)
+		return
+	}
+	source, err := getRemoteSourceByID(a.db, sourceID)
+	if err != nil {
+		http.Error(w, "unknown remote source", http.StatusBadRequest)
+		return
+	}
+
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{
-		AccessToken: token.AccessToken,
-		TokenType:   "bearer",
-	})
-	httpClient := oauth2.NewClient(ctx, tokenSource)
-	client := github.NewClient(httpClient)
-	client.UserAgent = "testpilot-gateway/1.0"
-	user, resp, err := client.Users.Get(ctx, "")
+	token, err := provider.OAuthConfig().Exchange(ctx, code)
 	if err != nil {
-		status := 0
-		if resp != nil {
-			status = resp.StatusCode
-		}
-		log.Printf("oauth callback user fetch failed status=%d err=%v", status, err)
-		http.Error(w, fmt.Sprintf("user fetch failed (github status=%d): %v", status, err), http.StatusBadGateway)
+		http.Error(w, "failed to exchange authorization code: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if strings.TrimSpace(token.AccessToken) == "" {
+		http.Error(w, "oauth exchange returned empty access token", http.StatusUnauthorized)
 		return
 	}
-	if user.GetID() == 0 || strings.TrimSpace(user.GetLogin()) == "" {
-		log.Printf("oauth callback user payload incomplete id=%d login=%q", user.GetID(), user.GetLogin())
-		http.Error(w, "user fetch failed: github user payload incomplete", http.StatusBadGateway)
+
+	remoteUser, err := provider.GetUser(ctx, token)
+	if err != nil {
+		log.Printf("oauth callback user fetch failed source=%s err=%v", source.Type, err)
+		http.Error(w, fmt.Sprintf("user fetch failed (%s): %v", source.Type, err), http.StatusBadGateway)
+		return
+	}
+	if remoteUser.ID == 0 || strings.TrimSpace(remoteUser.Login) == "" {
+		log.Printf("oauth callback user payload incomplete source=%s id=%d login=%q", source.Type, remoteUser.ID, remoteUser.Login)
+		http.Error(w, "user fetch failed: remote user payload incomplete", http.StatusBadGateway)
 		return
 	}
 
-	userID, err := upsertUser(a.db, user.GetID(), user.GetLogin(), token.AccessToken)
+	userID, err := upsertUser(a.db, a.kek, source.ID, remoteUser.ID, remoteUser.Login, &storedToken{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	})
 	if err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return
@@ -158,23 +181,52 @@ func (a *App) handleGitHubInstallStart(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, parsed.String(), http.StatusFound)
 }
 
+// issueJWT signs an RS256 token for userID using the current signing key
+// in a.jwtKeys. The "kid" header lets verifiers (this service and others,
+// via handleJWKS) pick the right public key even across a key rotation.
 func (a *App) issueJWT(userID int64) (string, error) {
+	signingKey := a.jwtKeys.Current()
 	claims := jwt.MapClaims{
 		"sub": userID,
 		"exp": time.Now().Add(24 * time.Hour).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(a.jwtKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.KeyID
+	return token.SignedString(signingKey.PrivateKey)
 }
 
-func (a *App) oauthConfig() *oauth2.Config {
-	return &oauth2.Config{
-		ClientID:     a.cfg.GitHubClientID,
-		ClientSecret: a.cfg.GitHubClientSecret,
-		RedirectURL:  a.cfg.GitHubOAuthRedirect,
-		Scopes:       []string{"repo", "read:user"},
-		Endpoint:     githuboauth.Endpoint,
+// remoteSourceFromRequest resolves the remote_sources row named by the
+// "source" path value, e.g. the "github" in /auth/github/login. Path
+// parsing follows the manual-split convention already used for the
+// /api/v1/ide/job_status/{id} family of routes.
+func (a *App) remoteSourceFromRequest(r *http.Request) (*RemoteSource, error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("missing remote source in path")
 	}
+	sourceSlug := parts[1]
+	return getRemoteSourceBySlug(a.db, sourceSlug)
+}
+
+func setOAuthCookies(w http.ResponseWriter, r *http.Request, state string, sourceID int64) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_state",
+		Value:    state,
+		HttpOnly: true,
+		Secure:   isHTTPSRequest(r),
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		MaxAge:   300,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_source_id",
+		Value:    fmt.Sprintf("%d", sourceID),
+		HttpOnly: true,
+		Secure:   isHTTPSRequest(r),
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		MaxAge:   300,
+	})
 }
 
 func randomState() (string, error) {
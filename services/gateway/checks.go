@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+
+	"git-app-gateway/analyzer"
+)
+
+// maxCheckRunAnnotations is the GitHub Checks API's per-request annotation
+// cap; runs with more issues than this still complete, just with the
+// overflow summarized in the check run's body instead of inlined.
+const maxCheckRunAnnotations = 50
+
+// completeCheckRun finalizes a Check Run started by runCheckForHeadSHA,
+// either with the analyzer's findings as inline annotations or, if runErr
+// is set, as a failed run explaining why no review could be produced.
+func (a *App) completeCheckRun(ctx context.Context, client *github.Client, repo *github.Repository, checkRunID int64, issues []analyzer.Issue, runErr error) {
+	update := github.UpdateCheckRunOptions{
+		Name:   "testpilot-review",
+		Status: github.String("completed"),
+	}
+
+	if runErr != nil {
+		update.Conclusion = github.String("failure")
+		update.Output = &github.CheckRunOutput{
+			Title:   github.String("Review failed"),
+			Summary: github.String(fmt.Sprintf("testpilot could not complete this review: %v", runErr)),
+		}
+	} else {
+		update.Conclusion = github.String(conclusionForIssues(issues))
+		update.Output = &github.CheckRunOutput{
+			Title:       github.String("Automated review"),
+			Summary:     github.String(checkRunSummary(issues)),
+			Annotations: annotationsForIssues(issues),
+		}
+	}
+
+	if _, _, err := client.Checks.UpdateCheckRun(ctx, repo.GetOwner().GetLogin(), repo.GetName(), checkRunID, update); err != nil {
+		// Best-effort: the check run is left "in_progress" on GitHub's side,
+		// which is at least visible to the reviewer as stuck rather than
+		// silently wrong.
+		return
+	}
+}
+
+func conclusionForIssues(issues []analyzer.Issue) string {
+	for _, issue := range issues {
+		if issue.Severity == analyzer.SeverityError {
+			return "failure"
+		}
+	}
+	return "success"
+}
+
+func checkRunSummary(issues []analyzer.Issue) string {
+	if len(issues) == 0 {
+		return "No issues found."
+	}
+	return fmt.Sprintf("Found %d issue(s) across the changed lines.", len(issues))
+}
+
+func annotationsForIssues(issues []analyzer.Issue) []*github.CheckRunAnnotation {
+	annotations := make([]*github.CheckRunAnnotation, 0, len(issues))
+	for _, issue := range issues {
+		if issue.File == "" || issue.Line == 0 {
+			continue
+		}
+		if len(annotations) >= maxCheckRunAnnotations {
+			break
+		}
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            github.String(issue.File),
+			StartLine:       github.Int(issue.Line),
+			EndLine:         github.Int(issue.Line),
+			AnnotationLevel: github.String(annotationLevel(issue.Severity)),
+			Message:         github.String(issue.Message),
+			Title:           github.String(issue.RuleID),
+		})
+	}
+	return annotations
+}
+
+func annotationLevel(severity analyzer.Severity) string {
+	switch severity {
+	case analyzer.SeverityError:
+		return "failure"
+	case analyzer.SeverityWarning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
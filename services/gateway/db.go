@@ -2,21 +2,57 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
+
+	"git-app-gateway/gitsource"
+	"git-app-gateway/tokencrypt"
 )
 
 type User struct {
-	ID          int64
-	GitHubID    int64
-	Login       string
-	AccessToken string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             int64
+	RemoteSourceID int64
+	RemoteUserID   int64
+	Login          string
+	AccessToken    string
+	RefreshToken   string
+	TokenExpiry    time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// RemoteSource is one registered forge instance (a row of remote_sources):
+// github.com, a self-hosted GitLab, a company Gitea, etc. Admins register
+// these so handleLogin/handleCallback can dispatch to the right
+// gitsource.Provider by slug.
+type RemoteSource struct {
+	ID           int64
+	Slug         string
+	Type         gitsource.Type
+	BaseURL      string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	CreatedAt    time.Time
+}
+
+// Config builds the gitsource.Config this row describes.
+func (s *RemoteSource) Config() gitsource.Config {
+	return gitsource.Config{
+		ID:           s.ID,
+		Type:         s.Type,
+		BaseURL:      s.BaseURL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  s.RedirectURL,
+	}
 }
 
-func initDB(dsn string) (*sql.DB, error) {
+func initDB(dsn string, kek []byte) (*sql.DB, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
@@ -25,42 +61,439 @@ func initDB(dsn string) (*sql.DB, error) {
 		return nil, err
 	}
 	_, err = db.Exec(`
+		create table if not exists remote_sources (
+			id serial primary key,
+			slug text unique not null,
+			type text not null,
+			base_url text not null default '',
+			client_id text not null,
+			client_secret text not null,
+			redirect_url text not null default '',
+			created_at timestamptz default now()
+		);
+
 		create table if not exists users (
 			id serial primary key,
-			github_id bigint unique not null,
+			remote_source_id integer not null references remote_sources(id),
+			remote_user_id bigint not null,
 			login text not null,
-			access_token text not null,
+			access_token_dek bytea not null,
+			access_token_nonce bytea not null,
+			access_token_ciphertext bytea not null,
+			refresh_token_dek bytea not null default '',
+			refresh_token_nonce bytea not null default '',
+			refresh_token_ciphertext bytea not null default '',
+			token_expiry timestamptz,
+			created_at timestamptz default now(),
+			updated_at timestamptz default now(),
+			unique (remote_source_id, remote_user_id)
+		);
+
+		create table if not exists review_runs (
+			id serial primary key,
+			repo text not null,
+			commit_sha text not null,
+			score integer not null,
+			summary text not null,
+			result_json jsonb not null,
+			created_at timestamptz default now()
+		);
+
+		create table if not exists installations (
+			id bigint primary key,
+			remote_source_id integer not null references remote_sources(id),
+			account_login text not null,
+			account_id bigint not null,
+			permissions jsonb not null default '{}',
+			events text[] not null default '{}',
 			created_at timestamptz default now(),
 			updated_at timestamptz default now()
 		);
+
+		create table if not exists jobs (
+			id bigserial primary key,
+			user_id bigint not null default 0,
+			kind text not null,
+			payload_json jsonb not null default '{}',
+			state text not null default 'pending',
+			progress integer not null default 0,
+			result_json jsonb,
+			error text not null default '',
+			created_at timestamptz default now(),
+			started_at timestamptz,
+			finished_at timestamptz,
+			cancel_requested boolean not null default false
+		);
 	`)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := migrateUserTokenColumns(db, kek); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
 
-func upsertUser(db *sql.DB, githubID int64, login, accessToken string) (int64, error) {
+// migrateUserTokenColumns upgrades a users table provisioned before
+// envelope encryption landed (plain `access_token text`): it adds the
+// access_token_*/refresh_token_* columns if a fresh `create table if not
+// exists` hasn't already, encrypts any still-plaintext value found in the
+// old access_token column under kek, and only then drops that column -
+// so an in-place upgrade never loses a user's existing session.
+func migrateUserTokenColumns(db *sql.DB, kek []byte) error {
+	_, err := db.Exec(`
+		alter table users add column if not exists access_token_dek bytea not null default '';
+		alter table users add column if not exists access_token_nonce bytea not null default '';
+		alter table users add column if not exists access_token_ciphertext bytea not null default '';
+		alter table users add column if not exists refresh_token_dek bytea not null default '';
+		alter table users add column if not exists refresh_token_nonce bytea not null default '';
+		alter table users add column if not exists refresh_token_ciphertext bytea not null default '';
+	`)
+	if err != nil {
+		return fmt.Errorf("add envelope-encryption columns: %w", err)
+	}
+
+	var hasLegacyColumn bool
+	err = db.QueryRow(`
+		select exists (
+			select 1 from information_schema.columns
+			where table_name = 'users' and column_name = 'access_token'
+		)
+	`).Scan(&hasLegacyColumn)
+	if err != nil {
+		return fmt.Errorf("check for legacy access_token column: %w", err)
+	}
+	if !hasLegacyColumn {
+		return nil
+	}
+
+	rows, err := db.Query(`
+		select id, access_token from users where access_token_ciphertext = ''
+	`)
+	if err != nil {
+		return fmt.Errorf("select legacy plaintext tokens: %w", err)
+	}
+	type legacyUser struct {
+		id    int64
+		token string
+	}
+	var pending []legacyUser
+	for rows.Next() {
+		var u legacyUser
+		if err := rows.Scan(&u.id, &u.token); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan legacy plaintext token: %w", err)
+		}
+		pending = append(pending, u)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, u := range pending {
+		env, err := tokencrypt.Seal(kek, u.token)
+		if err != nil {
+			return fmt.Errorf("encrypt legacy access token for user %d: %w", u.id, err)
+		}
+		if _, err := db.Exec(`
+			update users
+			set access_token_dek = $1, access_token_nonce = $2, access_token_ciphertext = $3
+			where id = $4
+		`, env.WrappedDEK, env.Nonce, env.Ciphertext, u.id); err != nil {
+			return fmt.Errorf("backfill encrypted access token for user %d: %w", u.id, err)
+		}
+	}
+
+	if _, err := db.Exec(`alter table users drop column access_token`); err != nil {
+		return fmt.Errorf("drop legacy access_token column: %w", err)
+	}
+	return nil
+}
+
+// upsertUser envelope-encrypts token.AccessToken and token.RefreshToken
+// under kek before they ever reach Postgres; see package tokencrypt.
+func upsertUser(db *sql.DB, kek []byte, remoteSourceID, remoteUserID int64, login string, token *storedToken) (int64, error) {
+	accessEnv, err := tokencrypt.Seal(kek, token.AccessToken)
+	if err != nil {
+		return 0, err
+	}
+	refreshEnv, err := tokencrypt.Seal(kek, token.RefreshToken)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int64
-	err := db.QueryRow(`
-		insert into users (github_id, login, access_token)
-		values ($1, $2, $3)
-		on conflict (github_id)
-		do update set login = excluded.login, access_token = excluded.access_token, updated_at = now()
+	err = db.QueryRow(`
+		insert into users (
+			remote_source_id, remote_user_id, login,
+			access_token_dek, access_token_nonce, access_token_ciphertext,
+			refresh_token_dek, refresh_token_nonce, refresh_token_ciphertext,
+			token_expiry
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		on conflict (remote_source_id, remote_user_id)
+		do update set
+			login = excluded.login,
+			access_token_dek = excluded.access_token_dek,
+			access_token_nonce = excluded.access_token_nonce,
+			access_token_ciphertext = excluded.access_token_ciphertext,
+			refresh_token_dek = excluded.refresh_token_dek,
+			refresh_token_nonce = excluded.refresh_token_nonce,
+			refresh_token_ciphertext = excluded.refresh_token_ciphertext,
+			token_expiry = excluded.token_expiry,
+			updated_at = now()
 		returning id;
-	`, githubID, login, accessToken).Scan(&id)
+	`, remoteSourceID, remoteUserID, login,
+		accessEnv.WrappedDEK, accessEnv.Nonce, accessEnv.Ciphertext,
+		refreshEnv.WrappedDEK, refreshEnv.Nonce, refreshEnv.Ciphertext,
+		token.Expiry).Scan(&id)
 	return id, err
 }
 
-func getUserByID(db *sql.DB, id int64) (*User, error) {
+// storedToken is the subset of oauth2.Token that upsertUser persists; kept
+// as its own type so db.go doesn't need to import golang.org/x/oauth2.
+type storedToken struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// getUserByID loads a user and transparently decrypts its tokens with kek.
+func getUserByID(db *sql.DB, kek []byte, id int64) (*User, error) {
 	row := db.QueryRow(`
-		select id, github_id, login, access_token, created_at, updated_at
+		select id, remote_source_id, remote_user_id, login,
+			access_token_dek, access_token_nonce, access_token_ciphertext,
+			refresh_token_dek, refresh_token_nonce, refresh_token_ciphertext,
+			token_expiry, created_at, updated_at
 		from users where id = $1
 	`, id)
+
 	user := &User{}
-	err := row.Scan(&user.ID, &user.GitHubID, &user.Login, &user.AccessToken, &user.CreatedAt, &user.UpdatedAt)
+	var accessEnv, refreshEnv tokencrypt.Envelope
+	var tokenExpiry sql.NullTime
+	err := row.Scan(
+		&user.ID, &user.RemoteSourceID, &user.RemoteUserID, &user.Login,
+		&accessEnv.WrappedDEK, &accessEnv.Nonce, &accessEnv.Ciphertext,
+		&refreshEnv.WrappedDEK, &refreshEnv.Nonce, &refreshEnv.Ciphertext,
+		&tokenExpiry, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tokenExpiry.Valid {
+		user.TokenExpiry = tokenExpiry.Time
+	}
+
+	user.AccessToken, err = tokencrypt.Open(kek, &accessEnv)
 	if err != nil {
 		return nil, err
 	}
+	if len(refreshEnv.Ciphertext) > 0 {
+		user.RefreshToken, err = tokencrypt.Open(kek, &refreshEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
+
+func getRemoteSourceByID(db *sql.DB, id int64) (*RemoteSource, error) {
+	row := db.QueryRow(`
+		select id, slug, type, base_url, client_id, client_secret, redirect_url, created_at
+		from remote_sources where id = $1
+	`, id)
+	return scanRemoteSource(row)
+}
+
+func getRemoteSourceBySlug(db *sql.DB, slug string) (*RemoteSource, error) {
+	row := db.QueryRow(`
+		select id, slug, type, base_url, client_id, client_secret, redirect_url, created_at
+		from remote_sources where slug = $1
+	`, slug)
+	return scanRemoteSource(row)
+}
+
+func listRemoteSources(db *sql.DB) ([]RemoteSource, error) {
+	rows, err := db.Query(`
+		select id, slug, type, base_url, client_id, client_secret, redirect_url, created_at
+		from remote_sources order by id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []RemoteSource
+	for rows.Next() {
+		source, err := scanRemoteSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+	}
+	return sources, rows.Err()
+}
+
+func insertRemoteSource(db *sql.DB, source RemoteSource) (int64, error) {
+	var id int64
+	err := db.QueryRow(`
+		insert into remote_sources (slug, type, base_url, client_id, client_secret, redirect_url)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id;
+	`, source.Slug, source.Type, source.BaseURL, source.ClientID, source.ClientSecret, source.RedirectURL).Scan(&id)
+	return id, err
+}
+
+// listUsersWithExpiringTokens returns every user whose stored access token
+// expires within window, for the background refresher to renew.
+func listUsersWithExpiringTokens(db *sql.DB, kek []byte, window time.Duration) ([]*User, error) {
+	rows, err := db.Query(`
+		select id from users
+		where token_expiry is not null and token_expiry < now() + $1::interval
+	`, window.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	users := make([]*User, 0, len(ids))
+	for _, id := range ids {
+		user, err := getUserByID(db, kek, id)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// saveReviewRun persists a synchronous /review_commit result so the async
+// review_repo_async/job_status endpoints can surface the same run later.
+func saveReviewRun(db *sql.DB, repo, commitSHA string, response ReviewResponse) error {
+	resultJSON, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		insert into review_runs (repo, commit_sha, score, summary, result_json)
+		values ($1, $2, $3, $4, $5)
+	`, repo, commitSHA, response.Score, response.Summary, resultJSON)
+	return err
+}
+
+// Installation is a GitHub App installation (a row of installations),
+// recorded once on the install callback and refreshed on reinstall/webhook.
+type Installation struct {
+	ID             int64
+	RemoteSourceID int64
+	AccountLogin   string
+	AccountID      int64
+	Permissions    json.RawMessage
+	Events         []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func upsertInstallation(db *sql.DB, inst Installation) error {
+	permissions := inst.Permissions
+	if permissions == nil {
+		permissions = json.RawMessage("{}")
+	}
+	_, err := db.Exec(`
+		insert into installations (id, remote_source_id, account_login, account_id, permissions, events)
+		values ($1, $2, $3, $4, $5, $6)
+		on conflict (id) do update set
+			account_login = excluded.account_login,
+			account_id = excluded.account_id,
+			permissions = excluded.permissions,
+			events = excluded.events,
+			updated_at = now()
+	`, inst.ID, inst.RemoteSourceID, inst.AccountLogin, inst.AccountID, permissions, pqStringArray(inst.Events))
+	return err
+}
+
+func getInstallationByID(db *sql.DB, id int64) (*Installation, error) {
+	row := db.QueryRow(`
+		select id, remote_source_id, account_login, account_id, permissions, events, created_at, updated_at
+		from installations where id = $1
+	`, id)
+	inst := &Installation{}
+	var events []byte
+	if err := row.Scan(&inst.ID, &inst.RemoteSourceID, &inst.AccountLogin, &inst.AccountID, &inst.Permissions, &events, &inst.CreatedAt, &inst.UpdatedAt); err != nil {
+		return nil, err
+	}
+	inst.Events = parsePQStringArray(events)
+	return inst, nil
+}
+
+// getInstallationByLogin finds the installation covering accountLogin
+// under source, for minting an installation token instead of falling
+// back to the user's own OAuth token.
+func getInstallationByLogin(db *sql.DB, remoteSourceID int64, accountLogin string) (*Installation, error) {
+	row := db.QueryRow(`
+		select id, remote_source_id, account_login, account_id, permissions, events, created_at, updated_at
+		from installations where remote_source_id = $1 and lower(account_login) = lower($2)
+	`, remoteSourceID, accountLogin)
+	inst := &Installation{}
+	var events []byte
+	if err := row.Scan(&inst.ID, &inst.RemoteSourceID, &inst.AccountLogin, &inst.AccountID, &inst.Permissions, &events, &inst.CreatedAt, &inst.UpdatedAt); err != nil {
+		return nil, err
+	}
+	inst.Events = parsePQStringArray(events)
+	return inst, nil
+}
+
+// pqStringArray renders a Go string slice as a Postgres text[] literal.
+func pqStringArray(values []string) string {
+	out := "{"
+	for i, v := range values {
+		if i > 0 {
+			out += ","
+		}
+		out += `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+	}
+	return out + "}"
+}
+
+// parsePQStringArray parses the {a,b,c} literal lib/pq returns for text[].
+func parsePQStringArray(raw []byte) []string {
+	s := strings.Trim(string(raw), "{}")
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.Trim(parts[i], `"`)
+	}
+	return parts
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRemoteSource(row rowScanner) (*RemoteSource, error) {
+	source := &RemoteSource{}
+	var typ string
+	err := row.Scan(&source.ID, &source.Slug, &typ, &source.BaseURL, &source.ClientID, &source.ClientSecret, &source.RedirectURL, &source.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	source.Type = gitsource.Type(typ)
+	return source, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	"git-app-gateway/gitsource"
+)
+
+// gitAskpassRequest is what the testpilot-git-askpass helper binary sends.
+// host/path describe the repo git is cloning/fetching, e.g.
+// host="github.com" path="owner/repo.git", so we can resolve both the
+// right RemoteSource and, once we have a provider client, the right repo
+// full name to check access against.
+type gitAskpassRequest struct {
+	UserJWT string `json:"user_jwt"`
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+}
+
+// gitAskpassResponse is shaped like the `git credential fill` protocol's
+// output fields so the helper binary can print them back to git verbatim.
+type gitAskpassResponse struct {
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleGitAskpass mints a short-lived credential for a single git
+// operation instead of ever handing the IDE extension the user's raw
+// stored access_token. It prefers a GitHub App installation token (scoped
+// to just that installation's repos) and falls back to the user's own
+// OAuth token when no matching install exists.
+func (a *App) handleGitAskpass(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req gitAskpassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := a.verifyUserJWT(req.UserJWT)
+	if err != nil {
+		http.Error(w, "invalid user_jwt", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := getUserByID(a.db, a.kek, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	source, err := remoteSourceForHost(a.db, req.Host)
+	if err != nil {
+		http.Error(w, "no remote source registered for host "+req.Host, http.StatusNotFound)
+		return
+	}
+
+	repoFullName := strings.TrimSuffix(strings.TrimPrefix(req.Path, "/"), ".git")
+	if err := a.validateRepoAccess(r.Context(), user, source, repoFullName); err != nil {
+		http.Error(w, "access denied: "+err.Error(), http.StatusForbidden)
+		return
+	}
+
+	token, username, err := a.shortLivedGitToken(r.Context(), user, source, repoFullName)
+	if err != nil {
+		http.Error(w, "failed to mint credential: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, gitAskpassResponse{
+		Protocol: "https",
+		Host:     req.Host,
+		Username: username,
+		Password: token,
+	})
+}
+
+// handleValidateRepoAccess exposes validateRepoAccess as its own endpoint
+// for callers (e.g. the VS Code extension, before it even shells out to
+// git) that just want a yes/no without minting a credential.
+func (a *App) handleValidateRepoAccess(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req gitAskpassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := a.verifyUserJWT(req.UserJWT)
+	if err != nil {
+		http.Error(w, "invalid user_jwt", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := getUserByID(a.db, a.kek, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	source, err := remoteSourceForHost(a.db, req.Host)
+	if err != nil {
+		http.Error(w, "no remote source registered for host "+req.Host, http.StatusNotFound)
+		return
+	}
+
+	repoFullName := strings.TrimSuffix(strings.TrimPrefix(req.Path, "/"), ".git")
+	if err := a.validateRepoAccess(r.Context(), user, source, repoFullName); err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"allowed": false, "reason": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"allowed": true})
+}
+
+// validateRepoAccess confirms user can see repoFullName under source
+// before a credential for it is ever minted, by listing the user's repos
+// through the same gitsource.Provider the rest of the gateway uses.
+func (a *App) validateRepoAccess(ctx context.Context, user *User, source *RemoteSource, repoFullName string) error {
+	if err := a.ensureFreshToken(ctx, user, source); err != nil {
+		return err
+	}
+
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		return err
+	}
+
+	token := &oauth2.Token{AccessToken: user.AccessToken}
+	repos, err := provider.ListRepos(ctx, token)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if strings.EqualFold(repo.FullName, repoFullName) {
+			return nil
+		}
+	}
+	return fmt.Errorf("repo %s not visible to this user", repoFullName)
+}
+
+// shortLivedGitToken prefers a GitHub App installation token scoped to
+// just this repo's installation; when none is on file it falls back to
+// the user's own OAuth access token (already refreshed by
+// validateRepoAccess's call to ensureFreshToken).
+func (a *App) shortLivedGitToken(ctx context.Context, user *User, source *RemoteSource, repoFullName string) (token, username string, err error) {
+	if source.Type == gitsource.TypeGitHub {
+		owner := strings.SplitN(repoFullName, "/", 2)[0]
+		if install, err := getInstallationByLogin(a.db, source.ID, owner); err == nil {
+			client, err := a.appClient()
+			if err == nil {
+				installToken, _, err := client.Apps.CreateInstallationToken(ctx, install.ID, nil)
+				if err == nil {
+					return installToken.GetToken(), "x-access-token", nil
+				}
+			}
+		}
+	}
+	return user.AccessToken, user.Login, nil
+}
+
+// verifyUserJWT validates a token minted by issueJWT and returns the
+// subject user ID, looking the signing key up by "kid" the same way
+// handleJWKS publishes it.
+func (a *App) verifyUserJWT(rawToken string) (int64, error) {
+	parsed, err := jwt.Parse(rawToken, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := a.jwtKeys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return &key.PrivateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil || !parsed.Valid {
+		return 0, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, fmt.Errorf("invalid claims")
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("missing sub claim")
+	}
+	return int64(sub), nil
+}
+
+// remoteSourceForHost maps a git host (e.g. "github.com",
+// "git.internal.example.com") to its registered RemoteSource. Public
+// hosts resolve to their well-known slug; anything else is looked up by
+// matching base_url, covering self-hosted GitLab/Gitea instances.
+func remoteSourceForHost(db *sql.DB, host string) (*RemoteSource, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	switch host {
+	case "github.com":
+		return getRemoteSourceBySlug(db, "github")
+	case "gitlab.com":
+		return getRemoteSourceBySlug(db, "gitlab")
+	case "bitbucket.org":
+		return getRemoteSourceBySlug(db, "bitbucket")
+	}
+
+	sources, err := listRemoteSources(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		if strings.Contains(strings.ToLower(sources[i].BaseURL), host) {
+			return &sources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no remote source registered for host %q", host)
+}
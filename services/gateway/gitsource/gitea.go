@@ -0,0 +1,149 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+func init() {
+	Register(TypeGitea, newGiteaProvider)
+}
+
+// giteaProvider talks to a self-hosted Gitea (or Forgejo) instance. Unlike
+// the SaaS forges, Gitea has no fixed OAuth endpoint, so BaseURL is
+// required on the remote_sources row.
+type giteaProvider struct {
+	cfg     Config
+	baseURL string
+}
+
+func newGiteaProvider(cfg Config) Provider {
+	return &giteaProvider{cfg: cfg, baseURL: baseURLOrDefault(cfg.BaseURL, "")}
+}
+
+func (p *giteaProvider) Type() Type { return TypeGitea }
+
+func (p *giteaProvider) OAuthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       []string{"read:user", "read:repository", "read:organization"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.baseURL + "/login/oauth/authorize",
+			TokenURL: p.baseURL + "/login/oauth/access_token",
+		},
+	}
+}
+
+func (p *giteaProvider) get(ctx context.Context, token *oauth2.Token, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitea: %s returned status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (p *giteaProvider) GetUser(ctx context.Context, token *oauth2.Token) (*User, error) {
+	var raw struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token, "/user", &raw); err != nil {
+		return nil, err
+	}
+	return &User{ID: raw.ID, Login: raw.Login, AvatarURL: raw.AvatarURL}, nil
+}
+
+func (p *giteaProvider) ListRepos(ctx context.Context, token *oauth2.Token) ([]Repo, error) {
+	var raw []struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		Private  bool   `json:"private"`
+		HTMLURL  string `json:"html_url"`
+	}
+	if err := p.get(ctx, token, "/user/repos?limit=100", &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Repo, 0, len(raw))
+	for _, r := range raw {
+		out = append(out, Repo{ID: r.ID, Name: r.Name, FullName: r.FullName, Private: r.Private, URL: r.HTMLURL})
+	}
+	return out, nil
+}
+
+func (p *giteaProvider) ListOrgs(ctx context.Context, token *oauth2.Token) ([]Org, error) {
+	var raw []struct {
+		ID        int64  `json:"id"`
+		UserName  string `json:"username"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token, "/user/orgs?limit=100", &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Org, 0, len(raw))
+	for _, o := range raw {
+		out = append(out, Org{ID: o.ID, Login: o.UserName, Type: "Organization", AvatarURL: o.AvatarURL})
+	}
+	return out, nil
+}
+
+func (p *giteaProvider) GetCommitDiff(ctx context.Context, token *oauth2.Token, repoFullName, sha string) (string, error) {
+	owner, repo, err := splitFullName(repoFullName)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/repos/%s/%s/git/commits/%s.diff", p.baseURL, owner, repo, sha), nil)
+	if err != nil {
+		return "", err
+	}
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gitea: diff for %s@%s returned status %d", repoFullName, sha, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func (p *giteaProvider) ParseWebhook(r *http.Request, secret string) (*WebhookEvent, error) {
+	kind := r.Header.Get("X-Gitea-Event")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret != "" {
+		signature := r.Header.Get("X-Gitea-Signature")
+		if !verifyHMACSHA256(secret, body, signature) {
+			return nil, fmt.Errorf("gitea: webhook signature mismatch")
+		}
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return &WebhookEvent{Kind: kind, Payload: payload}, nil
+}
@@ -0,0 +1,103 @@
+package gitsource
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/go-github/v61/github"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+)
+
+func init() {
+	Register(TypeGitHub, newGitHubProvider)
+}
+
+type githubProvider struct {
+	cfg Config
+}
+
+func newGitHubProvider(cfg Config) Provider {
+	return &githubProvider{cfg: cfg}
+}
+
+func (p *githubProvider) Type() Type { return TypeGitHub }
+
+func (p *githubProvider) OAuthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       []string{"repo", "read:user"},
+		Endpoint:     githuboauth.Endpoint,
+	}
+}
+
+func (p *githubProvider) client(ctx context.Context, token *oauth2.Token) *github.Client {
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	client := github.NewClient(httpClient)
+	client.UserAgent = "testpilot-gateway/1.0"
+	return client
+}
+
+func (p *githubProvider) GetUser(ctx context.Context, token *oauth2.Token) (*User, error) {
+	user, _, err := p.client(ctx, token).Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return &User{ID: user.GetID(), Login: user.GetLogin(), AvatarURL: user.GetAvatarURL()}, nil
+}
+
+func (p *githubProvider) ListRepos(ctx context.Context, token *oauth2.Token) ([]Repo, error) {
+	repos, _, err := p.client(ctx, token).Repositories.List(ctx, "", &github.RepositoryListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+		Visibility:  "all",
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Repo, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, Repo{ID: r.GetID(), Name: r.GetName(), FullName: r.GetFullName(), Private: r.GetPrivate(), URL: r.GetHTMLURL()})
+	}
+	return out, nil
+}
+
+func (p *githubProvider) ListOrgs(ctx context.Context, token *oauth2.Token) ([]Org, error) {
+	orgs, _, err := p.client(ctx, token).Organizations.List(ctx, "", &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Org, 0, len(orgs))
+	for _, o := range orgs {
+		out = append(out, Org{ID: o.GetID(), Login: o.GetLogin(), Type: o.GetType(), AvatarURL: o.GetAvatarURL()})
+	}
+	return out, nil
+}
+
+func (p *githubProvider) GetCommitDiff(ctx context.Context, token *oauth2.Token, repoFullName, sha string) (string, error) {
+	owner, repo, err := splitFullName(repoFullName)
+	if err != nil {
+		return "", err
+	}
+	client := p.client(ctx, token)
+	opts := github.RawOptions{Type: github.Diff}
+	raw, _, err := client.Repositories.GetCommitRaw(ctx, owner, repo, sha, opts)
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (p *githubProvider) ParseWebhook(r *http.Request, secret string) (*WebhookEvent, error) {
+	payload, err := github.ValidatePayload(r, []byte(secret))
+	if err != nil {
+		return nil, err
+	}
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		return nil, err
+	}
+	kind := github.WebHookType(r)
+	return &WebhookEvent{Kind: kind, Payload: event}, nil
+}
@@ -0,0 +1,160 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	gitlaboauth "golang.org/x/oauth2/gitlab"
+)
+
+func init() {
+	Register(TypeGitLab, newGitLabProvider)
+}
+
+type gitlabProvider struct {
+	cfg     Config
+	baseURL string
+}
+
+func newGitLabProvider(cfg Config) Provider {
+	return &gitlabProvider{cfg: cfg, baseURL: baseURLOrDefault(cfg.BaseURL, "https://gitlab.com")}
+}
+
+func (p *gitlabProvider) Type() Type { return TypeGitLab }
+
+func (p *gitlabProvider) OAuthConfig() *oauth2.Config {
+	endpoint := gitlaboauth.Endpoint
+	if p.baseURL != "https://gitlab.com" {
+		endpoint = oauth2.Endpoint{
+			AuthURL:  p.baseURL + "/oauth/authorize",
+			TokenURL: p.baseURL + "/oauth/token",
+		}
+	}
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       []string{"read_user", "read_api"},
+		Endpoint:     endpoint,
+	}
+}
+
+func (p *gitlabProvider) httpClient(ctx context.Context, token *oauth2.Token) *http.Client {
+	return oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+}
+
+func (p *gitlabProvider) get(ctx context.Context, token *oauth2.Token, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/v4"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient(ctx, token).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitlab: %s returned status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (p *gitlabProvider) GetUser(ctx context.Context, token *oauth2.Token) (*User, error) {
+	var raw struct {
+		ID        int64  `json:"id"`
+		Username  string `json:"username"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token, "/user", &raw); err != nil {
+		return nil, err
+	}
+	return &User{ID: raw.ID, Login: raw.Username, AvatarURL: raw.AvatarURL}, nil
+}
+
+func (p *gitlabProvider) ListRepos(ctx context.Context, token *oauth2.Token) ([]Repo, error) {
+	var raw []struct {
+		ID                int64  `json:"id"`
+		Name              string `json:"name"`
+		PathWithNamespace string `json:"path_with_namespace"`
+		Visibility        string `json:"visibility"`
+		WebURL            string `json:"web_url"`
+	}
+	if err := p.get(ctx, token, "/projects?membership=true&per_page=100", &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Repo, 0, len(raw))
+	for _, r := range raw {
+		out = append(out, Repo{ID: r.ID, Name: r.Name, FullName: r.PathWithNamespace, Private: r.Visibility != "public", URL: r.WebURL})
+	}
+	return out, nil
+}
+
+func (p *gitlabProvider) ListOrgs(ctx context.Context, token *oauth2.Token) ([]Org, error) {
+	var raw []struct {
+		ID        int64  `json:"id"`
+		Path      string `json:"path"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, token, "/groups?min_access_level=10&per_page=100", &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Org, 0, len(raw))
+	for _, g := range raw {
+		out = append(out, Org{ID: g.ID, Login: g.Path, Type: "Group", AvatarURL: g.AvatarURL})
+	}
+	return out, nil
+}
+
+type gitlabDiffFile struct {
+	Diff    string `json:"diff"`
+	NewPath string `json:"new_path"`
+	OldPath string `json:"old_path"`
+}
+
+func (p *gitlabProvider) GetCommitDiff(ctx context.Context, token *oauth2.Token, repoFullName, sha string) (string, error) {
+	var raw []gitlabDiffFile
+	projectID := pathEscape(repoFullName)
+	if err := p.get(ctx, token, fmt.Sprintf("/projects/%s/repository/commits/%s/diff", projectID, sha), &raw); err != nil {
+		return "", err
+	}
+	return renderUnifiedDiff(raw), nil
+}
+
+func (p *gitlabProvider) ParseWebhook(r *http.Request, secret string) (*WebhookEvent, error) {
+	if secret != "" && r.Header.Get("X-Gitlab-Token") != secret {
+		return nil, fmt.Errorf("gitlab: webhook token mismatch")
+	}
+	kind := r.Header.Get("X-Gitlab-Event")
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	return &WebhookEvent{Kind: kind, Payload: payload}, nil
+}
+
+func renderUnifiedDiff(files []gitlabDiffFile) string {
+	var out string
+	for _, f := range files {
+		out += fmt.Sprintf("diff --git a/%s b/%s\n", f.OldPath, f.NewPath)
+		out += f.Diff
+		if len(f.Diff) == 0 || f.Diff[len(f.Diff)-1] != '\n' {
+			out += "\n"
+		}
+	}
+	return out
+}
+
+func pathEscape(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' {
+			out = append(out, '%', '2', 'F')
+			continue
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
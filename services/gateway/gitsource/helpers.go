@@ -0,0 +1,37 @@
+package gitsource
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// splitFullName splits an "owner/repo" style full name into its two parts.
+func splitFullName(fullName string) (owner, repo string, err error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("gitsource: invalid repo full name %q", fullName)
+	}
+	return parts[0], parts[1], nil
+}
+
+// baseURLOrDefault returns cfg's BaseURL with trailing slashes trimmed, or
+// fallback when the source didn't configure one (i.e. the SaaS instance).
+func baseURLOrDefault(base, fallback string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		return fallback
+	}
+	return base
+}
+
+// verifyHMACSHA256 checks a hex-encoded HMAC-SHA256 signature over body,
+// as used by Gitea's X-Gitea-Signature webhook header.
+func verifyHMACSHA256(secret string, body []byte, signatureHex string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signatureHex)))
+}
@@ -0,0 +1,123 @@
+// Package gitsource abstracts away the differences between the forges
+// testpilot can review commits against. A Provider knows how to run the
+// OAuth dance, normalize user/repo/org payloads, fetch a commit diff, and
+// parse that forge's webhook format; callers (auth/org/repo handlers)
+// should never import a forge-specific SDK directly.
+package gitsource
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// Type identifies a concrete Provider implementation. It is stored on
+// remote_sources.type and used to look up the right constructor.
+type Type string
+
+const (
+	TypeGitHub    Type = "github"
+	TypeGitLab    Type = "gitlab"
+	TypeBitbucket Type = "bitbucket"
+	TypeGitea     Type = "gitea"
+)
+
+// Config describes a single registered remote source (one row of
+// remote_sources). BaseURL is ignored for github.com/gitlab.com/bitbucket.org
+// and required for self-hosted Gitea/GitLab/Bitbucket Server instances.
+type Config struct {
+	ID           int64
+	Type         Type
+	BaseURL      string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// User is the normalized identity returned by GetUser.
+type User struct {
+	ID        int64
+	Login     string
+	AvatarURL string
+}
+
+// Repo is the normalized repository summary returned by ListRepos.
+type Repo struct {
+	ID       int64
+	Name     string
+	FullName string
+	Private  bool
+	URL      string
+}
+
+// Org is the normalized organization/group summary returned by ListOrgs.
+type Org struct {
+	ID        int64
+	Login     string
+	Type      string
+	AvatarURL string
+}
+
+// WebhookEvent is the normalized shape produced by ParseWebhook. Kind is
+// provider-specific ("push", "pull_request", "merge_request", ...) and
+// Payload is the provider's own decoded event, kept as `any` so dispatch
+// code can type-switch on it when it needs provider-specific fields.
+type WebhookEvent struct {
+	Kind    string
+	Payload any
+}
+
+// Provider is implemented once per forge (github, gitlab, bitbucket, gitea).
+// Every method that talks to the forge's API takes the OAuth token for the
+// calling user so implementations stay stateless and safe to share.
+type Provider interface {
+	// Type returns the provider's registered Type, e.g. for logging.
+	Type() Type
+
+	// OAuthConfig returns the oauth2.Config for this source, wired up with
+	// the source's client credentials, redirect URL, and forge endpoint.
+	OAuthConfig() *oauth2.Config
+
+	GetUser(ctx context.Context, token *oauth2.Token) (*User, error)
+	ListRepos(ctx context.Context, token *oauth2.Token) ([]Repo, error)
+	ListOrgs(ctx context.Context, token *oauth2.Token) ([]Org, error)
+
+	// GetCommitDiff returns the unified diff for a single commit on
+	// repoFullName (owner/repo, or namespace/project for GitLab).
+	GetCommitDiff(ctx context.Context, token *oauth2.Token, repoFullName, sha string) (string, error)
+
+	// ParseWebhook validates and decodes an inbound webhook request. It
+	// must not consume r.Body before signature validation fails.
+	ParseWebhook(r *http.Request, secret string) (*WebhookEvent, error)
+}
+
+// Factory builds a Provider from a registered source Config.
+type Factory func(cfg Config) Provider
+
+var registry = map[Type]Factory{}
+
+// Register adds a Factory for typ. Called from each implementation's
+// init() so the registry is populated by importing the package alone.
+func Register(typ Type, factory Factory) {
+	registry[typ] = factory
+}
+
+// New looks up the Factory for cfg.Type and builds a Provider for it.
+func New(cfg Config) (Provider, error) {
+	factory, ok := registry[cfg.Type]
+	if !ok {
+		return nil, &UnsupportedTypeError{Type: cfg.Type}
+	}
+	return factory(cfg), nil
+}
+
+// UnsupportedTypeError is returned by New when no Provider is registered
+// for the requested Type.
+type UnsupportedTypeError struct {
+	Type Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "gitsource: unsupported provider type " + string(e.Type)
+}
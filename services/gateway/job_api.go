@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-app-gateway/analyzer"
+	"git-app-gateway/jobs"
+)
+
+const reviewRepoJobKind = "review_repo_async"
+
+// reviewJobPayload is what handleEnqueueReviewJob stores in jobs.payload_json
+// and the worker reads back out to run the analyzer pipeline.
+type reviewJobPayload struct {
+	Repo      string   `json:"repo"`
+	CommitSHA string   `json:"commit_sha"`
+	Diff      string   `json:"diff"`
+	Files     []string `json:"files"`
+}
+
+// handleEnqueueReviewJob backs POST /api/v1/jobs/review: it persists a
+// pending job row and returns immediately, leaving the actual analyzer run
+// to startJobWorker. This is the Postgres-backed counterpart to the
+// in-memory ScanJob flow in scan_api.go, for callers that want real
+// cancel/status support instead of a best-effort in-process map.
+func (a *App) handleEnqueueReviewJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req reviewJobPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+	if req.Diff == "" {
+		http.Error(w, "Diff is required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := jobs.Enqueue(a.db, userIDFromRequest(r), reviewRepoJobKind, req)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"job_id": job.ID, "state": job.State})
+}
+
+// handleJobShow backs GET /api/v1/jobs/{id}. Named distinctly from
+// scan_api.go's handleJobStatus, which serves the older in-memory
+// /api/v1/ide/job_status/{id} path for the AI-Core-proxied upload flow.
+func (a *App) handleJobShow(w http.ResponseWriter, r *http.Request) {
+	id, err := jobIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := jobs.Get(a.db, id)
+	if err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleJobCancel backs DELETE /api/v1/jobs/{id}: it only flags the job for
+// cancellation. The worker running it is responsible for noticing
+// cancel_requested between analyzer stages and stopping cooperatively.
+func (a *App) handleJobCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := jobIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	if err := jobs.RequestCancel(a.db, id); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleJobEvents backs GET /api/v1/jobs/{id}/events, streaming progress as
+// server-sent events so the VS Code extension doesn't need to poll
+// handleJobStatus.
+func (a *App) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := jobIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			job, err := jobs.Get(a.db, id)
+			if err != nil {
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+				flusher.Flush()
+				return
+			}
+
+			payload, _ := json.Marshal(job)
+			fmt.Fprintf(w, "event: progress\ndata: %s\n\n", payload)
+			flusher.Flush()
+
+			switch job.State {
+			case jobs.StateSucceeded, jobs.StateFailed, jobs.StateCancelled:
+				return
+			}
+		}
+	}
+}
+
+// startJobWorker polls for pending review_repo_async jobs and runs them
+// through the analyzer pipeline, checking for cancellation between stages.
+// Run as a background goroutine from main, the same way
+// startTokenRefresher is (see refresher.go).
+func (a *App) startJobWorker(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.runNextReviewJob(ctx)
+		}
+	}
+}
+
+func (a *App) runNextReviewJob(ctx context.Context) {
+	job, err := jobs.Lease(a.db, reviewRepoJobKind)
+	if err != nil {
+		log.Printf("[gateway-job-worker] lease error: %v", err)
+		return
+	}
+	if job == nil {
+		return
+	}
+
+	var payload reviewJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		_ = jobs.Fail(a.db, job.ID, err)
+		return
+	}
+
+	if cancelled, _ := jobs.IsCancelRequested(a.db, job.ID); cancelled {
+		_ = jobs.Cancel(a.db, job.ID)
+		return
+	}
+
+	pipeline := a.analyzerPipeline()
+	issues, stageResults := pipeline.Run(ctx, analyzer.Request{
+		Repo:      payload.Repo,
+		CommitSHA: payload.CommitSHA,
+		Diff:      payload.Diff,
+		Files:     payload.Files,
+	})
+	_ = jobs.UpdateProgress(a.db, job.ID, 75)
+
+	if cancelled, _ := jobs.IsCancelRequested(a.db, job.ID); cancelled {
+		_ = jobs.Cancel(a.db, job.ID)
+		return
+	}
+
+	issues = analyzer.Filter(issues, analyzer.ParseDiffScope(payload.Diff))
+	response := buildReviewResponse(issues, stageResults)
+
+	if err := jobs.Complete(a.db, job.ID, response); err != nil {
+		log.Printf("[gateway-job-worker] failed to record result for job %d: %v", job.ID, err)
+	}
+}
+
+func jobIDFromPath(path string) (int64, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "jobs" && i+1 < len(parts) {
+			return strconv.ParseInt(parts[i+1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("job id not found in path %q", path)
+}
+
+// userIDFromRequest has no auth context wired into the job endpoints yet,
+// so jobs are recorded with a 0 (anonymous) owner for now.
+func userIDFromRequest(r *http.Request) int64 {
+	return 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"git-app-gateway/jobevents"
+)
+
+// handleScanJobEvents backs GET /api/v1/ide/job_events/{id}: it replays
+// the job's already-recorded logs (so a client connecting mid-run isn't
+// missing history) and then streams live updates published by
+// processScanJob, instead of the client polling handleJobStatus on a
+// timer. Named distinctly from job_api.go's handleJobEvents, which
+// streams the unrelated Postgres-backed review-job queue from chunk0-5.
+func (app *App) handleScanJobEvents(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 6 {
+		http.Error(w, "Invalid request path", http.StatusBadRequest)
+		return
+	}
+	jobID := parts[5]
+
+	job, err := app.store.Get(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for _, line := range job.Logs {
+		writeSSE(w, "log", line)
+	}
+	writeSSE(w, "status", job.Status)
+	flusher.Flush()
+
+	switch job.Status {
+	case "completed":
+		writeSSE(w, "result", job.Result)
+		flusher.Flush()
+		return
+	case "failed":
+		writeSSE(w, "error", job.Error)
+		flusher.Flush()
+		return
+	case "cancelled":
+		return
+	}
+
+	events, cancel := app.events.Subscribe(jobID)
+	defer cancel()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSE(w, event.Type, event.Payload)
+			flusher.Flush()
+			if event.Type == "result" || event.Type == "error" {
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, eventType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+}
+
+// publishStatus is a small helper so processScanJob's update/SetResult/
+// SetError call sites stay one line each; see jobevents.Broker.
+func (app *App) publishStatus(jobID, status string) {
+	app.events.Publish(jobevents.Event{Type: "status", JobID: jobID, Payload: status})
+}
+
+func (app *App) publishLog(jobID, line string) {
+	app.events.Publish(jobevents.Event{Type: "log", JobID: jobID, Payload: line})
+}
+
+func (app *App) publishResult(jobID string, result *ScanResult) {
+	app.events.Publish(jobevents.Event{Type: "result", JobID: jobID, Payload: result})
+}
+
+func (app *App) publishError(jobID, message string) {
+	app.events.Publish(jobevents.Event{Type: "error", JobID: jobID, Payload: message})
+}
@@ -0,0 +1,139 @@
+// Package jobevents is a pub/sub broker for scan job progress: it lets
+// processScanJob publish "log"/"status"/"result"/"error" events and lets
+// the SSE handler at /api/v1/ide/job_events/{id} subscribe and replay
+// them live, instead of every client polling job_status on a timer.
+package jobevents
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event is one update about a single job. Type is one of "log", "status",
+// "result", "error" — the same vocabulary the SSE handler writes as the
+// event-stream "event:" field.
+type Event struct {
+	Type    string `json:"type"`
+	JobID   string `json:"job_id"`
+	Payload any    `json:"payload"`
+}
+
+// Broker lets callers publish job events and subscribe to a single job's
+// stream. Subscribe's cancel func must be called once the subscriber is
+// done, to release the channel/goroutine backing it.
+type Broker interface {
+	Publish(event Event)
+	Subscribe(jobID string) (events <-chan Event, cancel func())
+}
+
+// New picks the configured backend: Redis pub/sub when redisAddr is set
+// (so events reach subscribers connected to a different gateway replica
+// than the one running the job), otherwise an in-process broker.
+func New(redisAddr string) (Broker, error) {
+	if redisAddr != "" {
+		return newRedisBroker(redisAddr)
+	}
+	return newMemBroker(), nil
+}
+
+// memBroker fans events out to in-process subscribers only; sufficient
+// for a single gateway replica.
+type memBroker struct {
+	mu   sync.Mutex
+	subs map[string][]chan Event
+}
+
+func newMemBroker() *memBroker {
+	return &memBroker{subs: make(map[string][]chan Event)}
+}
+
+func (b *memBroker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs[event.JobID] {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: drop rather than block the publisher.
+		}
+	}
+}
+
+func (b *memBroker) Subscribe(jobID string) (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+
+	b.mu.Lock()
+	b.subs[jobID] = append(b.subs[jobID], ch)
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[jobID]
+		for i, existing := range subs {
+			if existing == ch {
+				b.subs[jobID] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// redisBroker fans events out via Redis pub/sub, so a subscriber
+// connected to one gateway replica sees events published by the replica
+// that's actually running the job.
+type redisBroker struct {
+	client *redis.Client
+}
+
+func newRedisBroker(addr string) (*redisBroker, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+	return &redisBroker{client: client}, nil
+}
+
+func (b *redisBroker) channel(jobID string) string {
+	return "scanjob:events:" + jobID
+}
+
+func (b *redisBroker) Publish(event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	b.client.Publish(context.Background(), b.channel(event.JobID), payload)
+}
+
+func (b *redisBroker) Subscribe(jobID string) (<-chan Event, func()) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	pubsub := b.client.Subscribe(ctx, b.channel(jobID))
+
+	out := make(chan Event, 32)
+	go func() {
+		defer close(out)
+		for msg := range pubsub.Channel() {
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	cancel := func() {
+		cancelCtx()
+		pubsub.Close()
+	}
+	return out, cancel
+}
@@ -0,0 +1,183 @@
+// Package jobs is a Postgres-backed queue for long-running async work (repo
+// reviews too large to finish within a single request). Workers lease rows
+// with SELECT ... FOR UPDATE SKIP LOCKED so more than one worker process can
+// run against the same table without double-processing a job.
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// State is a Job's lifecycle stage.
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Job is a row of the jobs table.
+type Job struct {
+	ID              int64
+	UserID          int64
+	Kind            string
+	Payload         json.RawMessage
+	State           State
+	Progress        int
+	Result          json.RawMessage
+	Error           string
+	CreatedAt       time.Time
+	StartedAt       sql.NullTime
+	FinishedAt      sql.NullTime
+	CancelRequested bool
+}
+
+// Enqueue inserts a pending job for a worker to pick up later.
+func Enqueue(db *sql.DB, userID int64, kind string, payload any) (*Job, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	var createdAt time.Time
+	err = db.QueryRow(`
+		insert into jobs (user_id, kind, payload_json, state)
+		values ($1, $2, $3, $4)
+		returning id, created_at
+	`, userID, kind, payloadJSON, StatePending).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		ID:        id,
+		UserID:    userID,
+		Kind:      kind,
+		Payload:   payloadJSON,
+		State:     StatePending,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// Get loads a job by ID.
+func Get(db *sql.DB, id int64) (*Job, error) {
+	row := db.QueryRow(`
+		select id, user_id, kind, payload_json, state, progress, result_json, error,
+			created_at, started_at, finished_at, cancel_requested
+		from jobs where id = $1
+	`, id)
+	return scanJob(row)
+}
+
+// RequestCancel flags a job for cooperative cancellation; the worker
+// running it observes this via IsCancelRequested between analyzer stages.
+func RequestCancel(db *sql.DB, id int64) error {
+	_, err := db.Exec(`update jobs set cancel_requested = true where id = $1`, id)
+	return err
+}
+
+// IsCancelRequested reports whether the caller of RequestCancel has asked
+// this job to stop.
+func IsCancelRequested(db *sql.DB, id int64) (bool, error) {
+	var cancelRequested bool
+	err := db.QueryRow(`select cancel_requested from jobs where id = $1`, id).Scan(&cancelRequested)
+	return cancelRequested, err
+}
+
+// Lease atomically claims one pending job of kind for this worker, marking
+// it running so a second worker's concurrent Lease call skips it instead of
+// blocking on it.
+func Lease(db *sql.DB, kind string) (*Job, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`
+		select id, user_id, kind, payload_json, state, progress, result_json, error,
+			created_at, started_at, finished_at, cancel_requested
+		from jobs
+		where kind = $1 and state = $2
+		order by created_at
+		for update skip locked
+		limit 1
+	`, kind, StatePending)
+
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`update jobs set state = $1, started_at = now() where id = $2`, StateRunning, job.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	job.State = StateRunning
+	return job, nil
+}
+
+// UpdateProgress reports 0-100 percent completion for the VS Code
+// extension's SSE stream to relay.
+func UpdateProgress(db *sql.DB, id int64, progress int) error {
+	_, err := db.Exec(`update jobs set progress = $1 where id = $2`, progress, id)
+	return err
+}
+
+// Complete marks a job succeeded with its final result payload.
+func Complete(db *sql.DB, id int64, result any) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		update jobs set state = $1, progress = 100, result_json = $2, finished_at = now()
+		where id = $3
+	`, StateSucceeded, resultJSON, id)
+	return err
+}
+
+// Fail marks a job failed with the error that stopped it.
+func Fail(db *sql.DB, id int64, cause error) error {
+	_, err := db.Exec(`
+		update jobs set state = $1, error = $2, finished_at = now()
+		where id = $3
+	`, StateFailed, cause.Error(), id)
+	return err
+}
+
+// Cancel marks a job cancelled once a worker observes cancel_requested.
+func Cancel(db *sql.DB, id int64) error {
+	_, err := db.Exec(`
+		update jobs set state = $1, finished_at = now()
+		where id = $2
+	`, StateCancelled, id)
+	return err
+}
+
+func scanJob(row *sql.Row) (*Job, error) {
+	job := &Job{}
+	var payload, result []byte
+	err := row.Scan(
+		&job.ID, &job.UserID, &job.Kind, &payload, &job.State, &job.Progress, &result, &job.Error,
+		&job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.CancelRequested,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.Result = result
+	return job, nil
+}
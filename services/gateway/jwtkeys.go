@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"sync"
+)
+
+// rsaKeyBits is the modulus size for generated signing keys. 2048 is the
+// minimum RSA size still considered acceptable for RS256 in 2026.
+const rsaKeyBits = 2048
+
+// JWTSigningKey is one RS256 keypair identified by KeyID, the "kid" JWTs
+// signed with it carry in their header.
+type JWTSigningKey struct {
+	KeyID      string
+	PrivateKey *rsa.PrivateKey
+}
+
+// JWTKeySet holds the key actively used to sign new tokens plus any
+// recently-retired keys still kept around to verify tokens issued before
+// the last rotation. Safe for concurrent use.
+type JWTKeySet struct {
+	mu      sync.RWMutex
+	current *JWTSigningKey
+	retired []*JWTSigningKey
+}
+
+// NewJWTKeySet generates a fresh signing key and returns a ready-to-use
+// key set. In production the initial key would instead be loaded from a
+// KMS-backed secret; see loadOrGenerateJWTKeySet in main (not present in
+// this chunk) for the env-var wiring.
+func NewJWTKeySet() (*JWTKeySet, error) {
+	key, err := generateJWTSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return &JWTKeySet{current: key}, nil
+}
+
+// Current returns the key new tokens should be signed with.
+func (s *JWTKeySet) Current() *JWTSigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Lookup finds a key (current or retired) by kid, for verifying a token
+// signed before the most recent rotation.
+func (s *JWTKeySet) Lookup(kid string) (*JWTSigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.current.KeyID == kid {
+		return s.current, true
+	}
+	for _, key := range s.retired {
+		if key.KeyID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate generates a new signing key, demotes the previous current key to
+// retired (kept only for verification), and drops retired keys beyond
+// keepRetired so the JWKS response doesn't grow without bound.
+func (s *JWTKeySet) Rotate(keepRetired int) error {
+	next, err := generateJWTSigningKey()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retired = append([]*JWTSigningKey{s.current}, s.retired...)
+	if len(s.retired) > keepRetired {
+		s.retired = s.retired[:keepRetired]
+	}
+	s.current = next
+	return nil
+}
+
+// PublicKeys returns every key (current then retired, newest first) in
+// the set, for rendering the JWKS document.
+func (s *JWTKeySet) PublicKeys() []*JWTSigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*JWTSigningKey, 0, 1+len(s.retired))
+	out = append(out, s.current)
+	out = append(out, s.retired...)
+	return out
+}
+
+func generateJWTSigningKey() (*JWTSigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	return &JWTSigningKey{KeyID: fingerprintPublicKey(&key.PublicKey), PrivateKey: key}, nil
+}
+
+// fingerprintPublicKey derives a short, stable kid from the key's modulus
+// so the same key always gets the same kid across process restarts.
+func fingerprintPublicKey(pub *rsa.PublicKey) string {
+	sum := sha256.Sum256(pub.N.Bytes())
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// jwk is a single entry of the JSON Web Key Set served at handleJWKS, per
+// RFC 7517/7518 for RSA keys used with RS256.
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (a *App) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	keys := a.jwtKeys.PublicKeys()
+	out := make([]jwk, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, jwk{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: key.KeyID,
+			N:   base64.RawURLEncoding.EncodeToString(key.PrivateKey.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(encodeExponent(key.PrivateKey.PublicKey.E)),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": out})
+}
+
+func encodeExponent(e int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(e))
+	i := 0
+	for i < len(buf)-1 && buf[i] == 0 {
+		i++
+	}
+	return buf[i:]
+}
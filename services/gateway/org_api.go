@@ -1,55 +1,72 @@
 package main
 
-// GitHub org listing endpoint for onboarding.
+// Org listing endpoint for onboarding, dispatched by the user's registered
+// remote source (GitHub, GitLab, Bitbucket, or Gitea).
 
 import (
 	"context"
 	"net/http"
 	"strings"
 
-	"github.com/google/go-github/v61/github"
 	"golang.org/x/oauth2"
+
+	"git-app-gateway/gitsource"
 )
 
 func (a *App) handleListOrgs(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(ctxKeyUserID{}).(int64)
-	user, err := getUserByID(a.db, userID)
+	user, err := getUserByID(a.db, a.kek, userID)
 	if err != nil {
 		http.Error(w, "user not found", http.StatusUnauthorized)
 		return
 	}
 
+	source, err := getRemoteSourceByID(a.db, user.RemoteSourceID)
+	if err != nil {
+		http.Error(w, "remote source not found", http.StatusInternalServerError)
+		return
+	}
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := context.Background()
+	if err := a.ensureFreshToken(ctx, user, source); err != nil {
+		http.Error(w, "token refresh failed", http.StatusBadGateway)
+		return
+	}
 	token := &oauth2.Token{AccessToken: user.AccessToken}
-	client := github.NewClient(oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(token)))
 
-	ghUser, _, err := client.Users.Get(context.Background(), "")
+	remoteUser, err := provider.GetUser(ctx, token)
 	if err != nil {
-		http.Error(w, "github error", http.StatusBadGateway)
+		http.Error(w, "remote source error", http.StatusBadGateway)
 		return
 	}
 
-	orgs, _, err := client.Organizations.List(context.Background(), "", &github.ListOptions{PerPage: 100})
+	orgs, err := provider.ListOrgs(ctx, token)
 	if err != nil {
-		http.Error(w, "github error", http.StatusBadGateway)
+		http.Error(w, "remote source error", http.StatusBadGateway)
 		return
 	}
 
 	orgList := make([]map[string]any, 0, len(orgs))
 	for _, org := range orgs {
 		orgList = append(orgList, map[string]any{
-			"id":         org.GetID(),
-			"login":      org.GetLogin(),
-			"type":       org.GetType(),
-			"avatar_url": org.GetAvatarURL(),
+			"id":         org.ID,
+			"login":      org.Login,
+			"type":       org.Type,
+			"avatar_url": org.AvatarURL,
 		})
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"account": map[string]any{
-			"id":         ghUser.GetID(),
-			"login":      ghUser.GetLogin(),
+			"id":         remoteUser.ID,
+			"login":      remoteUser.Login,
 			"type":       "User",
-			"avatar_url": ghUser.GetAvatarURL(),
+			"avatar_url": remoteUser.AvatarURL,
 		},
 		"orgs":        orgList,
 		"install_url": strings.TrimSpace(a.cfg.GitHubAppInstallURL),
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// authorizeRequest is sent to AI Core before the gateway ever reads an
+// upload's body, mirroring the pattern GitLab Workhorse uses in front of
+// its artifact/LFS uploads: the backend decides policy (quota, billing,
+// rate limits), the proxy just enforces the decision while streaming.
+type authorizeRequest struct {
+	UserID        int64  `json:"user_id"`
+	ContentLength int64  `json:"content_length"`
+	Repo          string `json:"repo,omitempty"`
+}
+
+// authorizeDecision is AI Core's response: where to spool the upload and
+// how big it's allowed to be for this user/repo.
+type authorizeDecision struct {
+	TempPath    string `json:"temp_path"`
+	MaximumSize int64  `json:"maximum_size"`
+}
+
+// preAuthorize asks AI Core whether this upload should even be accepted,
+// before ParseMultipartForm/spool.Stream reads a single byte of the body.
+// A non-nil error's statusCode is the status the caller should return to
+// the client as-is (401/403 from AI Core propagate unchanged); any other
+// failure (AI Core unreachable, bad JSON) is reported as 502.
+func (a *App) preAuthorize(r *http.Request, repoHint string) (decision *authorizeDecision, statusCode int, err error) {
+	aiCoreURL := os.Getenv("AI_CORE_URL")
+	if strings.TrimSpace(a.cfg.AICoreURL) != "" {
+		aiCoreURL = a.cfg.AICoreURL
+	}
+	if aiCoreURL == "" {
+		aiCoreURL = "http://ai-core:3000"
+	}
+
+	body, err := json.Marshal(authorizeRequest{
+		UserID:        userIDFromAuthHeader(a, r),
+		ContentLength: r.ContentLength,
+		Repo:          repoHint,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, strings.TrimRight(aiCoreURL, "/")+"/api/v1/ide/authorize", bytes.NewReader(body))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("ai core unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, resp.StatusCode, fmt.Errorf("ai core denied upload")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, http.StatusBadGateway, fmt.Errorf("ai core authorize returned status %d", resp.StatusCode)
+	}
+
+	var out authorizeDecision
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("invalid authorize response: %w", err)
+	}
+	return &out, http.StatusOK, nil
+}
+
+// userIDFromAuthHeader pulls the caller's user ID out of an
+// "Authorization: Bearer <jwt>" header, returning 0 (anonymous) if it's
+// missing or invalid rather than failing the request here — preAuthorize
+// itself is what enforces access, AI Core is free to treat user_id 0 as
+// unauthenticated.
+func userIDFromAuthHeader(a *App, r *http.Request) int64 {
+	header := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header {
+		return 0
+	}
+	userID, err := a.verifyUserJWT(token)
+	if err != nil {
+		return 0
+	}
+	return userID
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"git-app-gateway/gitsource"
+)
+
+// tokenRefreshWindow is how far ahead of expiry a token is considered due
+// for renewal, both for the inline check in ensureFreshToken and for the
+// background sweep in refreshExpiringTokens.
+const tokenRefreshWindow = 5 * time.Minute
+
+// ensureFreshToken refreshes user's stored access token in place if it is
+// within tokenRefreshWindow of expiry, so ListRepos/ListOrgs/GetCommitDiff
+// calls never hand a provider a token that is about to be rejected.
+func (a *App) ensureFreshToken(ctx context.Context, user *User, source *RemoteSource) error {
+	if user.TokenExpiry.IsZero() || time.Until(user.TokenExpiry) > tokenRefreshWindow {
+		return nil
+	}
+	if user.RefreshToken == "" {
+		return nil // nothing we can do; caller proceeds with the token it has
+	}
+
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		return err
+	}
+
+	current := &oauth2.Token{AccessToken: user.AccessToken, RefreshToken: user.RefreshToken, Expiry: user.TokenExpiry}
+	refreshed, err := provider.OAuthConfig().TokenSource(ctx, current).Token()
+	if err != nil {
+		return err
+	}
+	if refreshed.AccessToken == user.AccessToken {
+		return nil
+	}
+
+	newRefreshToken := refreshed.RefreshToken
+	if newRefreshToken == "" {
+		newRefreshToken = user.RefreshToken
+	}
+	if _, err := upsertUser(a.db, a.kek, user.RemoteSourceID, user.RemoteUserID, user.Login, &storedToken{
+		AccessToken:  refreshed.AccessToken,
+		RefreshToken: newRefreshToken,
+		Expiry:       refreshed.Expiry,
+	}); err != nil {
+		return err
+	}
+
+	user.AccessToken = refreshed.AccessToken
+	user.RefreshToken = newRefreshToken
+	user.TokenExpiry = refreshed.Expiry
+	return nil
+}
+
+// startTokenRefresher runs refreshExpiringTokens on a fixed interval until
+// ctx is cancelled. Call once from main() alongside initDB.
+func (a *App) startTokenRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.refreshExpiringTokens(ctx)
+			}
+		}
+	}()
+}
+
+// refreshExpiringTokens proactively renews every user whose token is due,
+// so requests that land between refresher ticks still get a live token
+// via the inline ensureFreshToken check.
+func (a *App) refreshExpiringTokens(ctx context.Context) {
+	users, err := listUsersWithExpiringTokens(a.db, a.kek, tokenRefreshWindow)
+	if err != nil {
+		log.Printf("[token-refresher] list expiring tokens failed: %v", err)
+		return
+	}
+	for _, user := range users {
+		source, err := getRemoteSourceByID(a.db, user.RemoteSourceID)
+		if err != nil {
+			log.Printf("[token-refresher] user=%d remote source lookup failed: %v", user.ID, err)
+			continue
+		}
+		if err := a.ensureFreshToken(ctx, user, source); err != nil {
+			log.Printf("[token-refresher] user=%d refresh failed: %v", user.ID, err)
+		}
+	}
+}
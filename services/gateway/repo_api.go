@@ -0,0 +1,62 @@
+package main
+
+// Repo listing endpoint for onboarding, dispatched by the user's
+// registered remote source.
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"git-app-gateway/gitsource"
+)
+
+func (a *App) handleListRepos(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(ctxKeyUserID{}).(int64)
+	user, err := getUserByID(a.db, a.kek, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusUnauthorized)
+		return
+	}
+
+	source, err := getRemoteSourceByID(a.db, user.RemoteSourceID)
+	if err != nil {
+		http.Error(w, "remote source not found", http.StatusInternalServerError)
+		return
+	}
+	provider, err := gitsource.New(source.Config())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := context.Background()
+	if err := a.ensureFreshToken(ctx, user, source); err != nil {
+		http.Error(w, "token refresh failed", http.StatusBadGateway)
+		return
+	}
+	token := &oauth2.Token{AccessToken: user.AccessToken}
+	repos, err := provider.ListRepos(ctx, token)
+	if err != nil {
+		http.Error(w, "remote source error", http.StatusBadGateway)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(repos))
+	for _, repo := range repos {
+		response = append(response, map[string]any{
+			"id":        repo.ID,
+			"name":      repo.Name,
+			"full_name": repo.FullName,
+			"private":   repo.Private,
+			"url":       repo.URL,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"repos":       response,
+		"install_url": strings.TrimSpace(a.cfg.GitHubAppInstallURL),
+	})
+}
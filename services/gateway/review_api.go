@@ -1,45 +1,54 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"net/http"
-	"regexp"
-	"strings"
+	"time"
+
+	"git-app-gateway/analyzer"
 )
 
 // ReviewRequest is the payload from VS Code extension
 type ReviewRequest struct {
-	Repo      string   `json:"repo"`
-	CommitSHA string   `json:"commit_sha"`
-	Diff      string   `json:"diff"`
-	Files     []string `json:"files"`
+	Repo       string   `json:"repo"`
+	CommitSHA  string   `json:"commit_sha"`
+	Diff       string   `json:"diff"`
+	Files      []string `json:"files"`
+	DiffScoped bool     `json:"diff_scoped"` // when true, drop findings on lines the diff didn't touch
 }
 
-// ReviewIssue represents a detected issue
+// ReviewIssue is the normalized finding shape returned to the extension,
+// keyed the same way analyzer.Issue is internally.
 type ReviewIssue struct {
-	Severity    string `json:"severity"` // "error", "warning", "info"
-	Description string `json:"description"`
-	File        string `json:"file,omitempty"`
-	Line        int    `json:"line,omitempty"`
+	Severity string `json:"severity"` // "error", "warning", "info"
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	RuleID   string `json:"rule_id,omitempty"`
+	Source   string `json:"source,omitempty"`
 }
 
-// ReviewSuggestion represents an improvement suggestion
-type ReviewSuggestion struct {
-	Description string `json:"description"`
-	File        string `json:"file,omitempty"`
-	Line        int    `json:"line,omitempty"`
+// StageStatus reports whether one analyzer stage completed, so a single
+// scanner outage is visible without failing the whole review.
+type StageStatus struct {
+	Stage string `json:"stage"`
+	Error string `json:"error,omitempty"`
 }
 
 // ReviewResponse is returned to the extension
 type ReviewResponse struct {
-	Summary     string             `json:"summary"`
-	Score       int                `json:"score"`
-	Issues      []ReviewIssue      `json:"issues"`
-	Suggestions []ReviewSuggestion `json:"suggestions"`
+	Summary string        `json:"summary"`
+	Score   int           `json:"score"`
+	Issues  []ReviewIssue `json:"issues"`
+	Stages  []StageStatus `json:"stages"`
 }
 
-// handleReviewCommit processes commit review requests from VS Code
-// TODO: Add JWT middleware here for production
+// handleReviewCommit runs the analyzer pipeline over a single commit's
+// diff and returns findings synchronously. Large repo-wide scans should
+// go through /api/v1/ide/review_repo_async instead; this endpoint is
+// meant for the VS Code extension's per-commit review-on-save flow.
 func (a *App) handleReviewCommit(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -57,83 +66,50 @@ func (a *App) handleReviewCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mock heuristic review is intentionally disabled.
-	// Clients must use AI-core async analysis endpoints via /api/v1/ide/review_repo_async.
-	http.Error(w, "review-commit endpoint disabled; use /api/v1/ide/review_repo_async for real analysis", http.StatusGone)
-}
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
 
-// analyzeDiff performs mock heuristic analysis on the diff
-func analyzeDiff(req ReviewRequest) ReviewResponse {
-	var issues []ReviewIssue
-	var suggestions []ReviewSuggestion
-	diff := req.Diff
-
-	// Heuristic 1: TODO/FIXME comments
-	todoRe := regexp.MustCompile(`(?i)(TODO|FIXME|XXX|HACK)`)
-	if todoRe.MatchString(diff) {
-		issues = append(issues, ReviewIssue{
-			Severity:    "warning",
-			Description: "Found TODO/FIXME comment that should be addressed",
-		})
-	}
-
-	// Heuristic 2: console.log in JS/TS
-	if strings.Contains(diff, "console.log") {
-		issues = append(issues, ReviewIssue{
-			Severity:    "info",
-			Description: "console.log statement should be removed before production",
-		})
-	}
+	pipeline := a.analyzerPipeline()
+	issues, stageResults := pipeline.Run(ctx, analyzer.Request{
+		Repo:      req.Repo,
+		CommitSHA: req.CommitSHA,
+		Diff:      req.Diff,
+		Files:     req.Files,
+	})
 
-	// Heuristic 3: Missing error handling in Go
-	if strings.Contains(diff, ".go") || hasGoFiles(req.Files) {
-		if strings.Contains(diff, "err :=") && !strings.Contains(diff, "if err != nil") {
-			issues = append(issues, ReviewIssue{
-				Severity:    "error",
-				Description: "Potential unhandled error in Go code",
-			})
-		}
+	if req.DiffScoped {
+		issues = analyzer.Filter(issues, analyzer.ParseDiffScope(req.Diff))
 	}
 
-	// Heuristic 4: Async without try/catch in JS/TS
-	if strings.Contains(diff, "async") && !strings.Contains(diff, "try") {
-		issues = append(issues, ReviewIssue{
-			Severity:    "warning",
-			Description: "Async function without try/catch error handling",
-		})
-	}
+	response := buildReviewResponse(issues, stageResults)
 
-	// Heuristic 5: Large diff suggests refactoring
-	lineCount := strings.Count(diff, "\n")
-	if lineCount > 200 {
-		suggestions = append(suggestions, ReviewSuggestion{
-			Description: "Large change detected. Consider breaking into smaller commits for easier review.",
-		})
+	if err := saveReviewRun(a.db, req.Repo, req.CommitSHA, response); err != nil {
+		// Non-fatal: the caller still gets their findings even if we
+		// failed to persist them for the async status endpoint to share.
+		log.Printf("[review-commit] failed to persist review run repo=%s sha=%s: %v", req.Repo, req.CommitSHA, err)
 	}
 
-	// Heuristic 6: No test files modified
-	hasTests := false
-	for _, f := range req.Files {
-		if strings.Contains(f, "test") || strings.Contains(f, "spec") || strings.Contains(f, "_test.go") {
-			hasTests = true
-			break
-		}
-	}
-	if !hasTests && len(req.Files) > 0 {
-		suggestions = append(suggestions, ReviewSuggestion{
-			Description: "Consider adding test coverage for these changes.",
-		})
-	}
+	writeJSON(w, http.StatusOK, response)
+}
 
-	// Calculate score based on issues
+func buildReviewResponse(issues []analyzer.Issue, stageResults []analyzer.StageResult) ReviewResponse {
+	reviewIssues := make([]ReviewIssue, 0, len(issues))
 	score := 100
 	for _, issue := range issues {
+		reviewIssues = append(reviewIssues, ReviewIssue{
+			Severity: string(issue.Severity),
+			Message:  issue.Message,
+			File:     issue.File,
+			Line:     issue.Line,
+			RuleID:   issue.RuleID,
+			Source:   issue.Source,
+		})
 		switch issue.Severity {
-		case "error":
+		case analyzer.SeverityError:
 			score -= 20
-		case "warning":
+		case analyzer.SeverityWarning:
 			score -= 10
-		case "info":
+		case analyzer.SeverityInfo:
 			score -= 5
 		}
 	}
@@ -141,34 +117,49 @@ func analyzeDiff(req ReviewRequest) ReviewResponse {
 		score = 0
 	}
 
-	// Generate summary
-	summary := generateSummary(score, len(issues), len(suggestions))
-
-	return ReviewResponse{
-		Summary:     summary,
-		Score:       score,
-		Issues:      issues,
-		Suggestions: suggestions,
+	stages := make([]StageStatus, 0, len(stageResults))
+	for _, result := range stageResults {
+		stages = append(stages, StageStatus{Stage: result.Stage, Error: result.Error})
 	}
-}
 
-func hasGoFiles(files []string) bool {
-	for _, f := range files {
-		if strings.HasSuffix(f, ".go") {
-			return true
-		}
+	return ReviewResponse{
+		Summary: generateSummary(score),
+		Score:   score,
+		Issues:  reviewIssues,
+		Stages:  stages,
 	}
-	return false
 }
 
-func generateSummary(score int, issueCount int, suggestionCount int) string {
-	if score >= 90 {
+func generateSummary(score int) string {
+	switch {
+	case score >= 90:
 		return "Excellent code quality! No significant issues found."
-	} else if score >= 70 {
+	case score >= 70:
 		return "Good code quality with minor suggestions for improvement."
-	} else if score >= 50 {
+	case score >= 50:
 		return "Code has some issues that should be addressed before merging."
-	} else {
+	default:
 		return "Several issues detected. Please review and fix before proceeding."
 	}
 }
+
+// analyzerPipeline assembles the stage set from configuration. Stages
+// whose backing tool/service isn't configured are simply omitted rather
+// than erroring, so a gateway with no SonarServiceURL still reviews with
+// whatever else is available.
+func (a *App) analyzerPipeline() *analyzer.Pipeline {
+	stages := []analyzer.Stage{analyzer.HeuristicsStage{}}
+
+	if a.cfg.SonarServiceURL != "" {
+		stages = append(stages, analyzer.NewSonarStage(a.cfg.SonarServiceURL))
+	}
+	if a.cfg.AnalyzerWorkDir != "" {
+		stages = append(stages,
+			analyzer.NewGolangciLintStage(a.cfg.AnalyzerWorkDir),
+			analyzer.NewESLintStage(a.cfg.AnalyzerWorkDir),
+			analyzer.NewSemgrepStage(a.cfg.AnalyzerWorkDir),
+		)
+	}
+
+	return analyzer.NewPipeline(stages...)
+}
@@ -5,117 +5,99 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
-)
 
-// Job Store (In-memory for MVP)
-var (
-	jobs    = make(map[string]*ScanJob)
-	jobsMut sync.RWMutex
+	"git-app-gateway/scanstore"
+	"git-app-gateway/spool"
 )
 
-type ScanJob struct {
-	ID        string      `json:"job_id"`
-	Status    string      `json:"status"` // pending, running, completed, failed, cancelled
-	Logs      []string    `json:"logs"`
-	Result    *ScanResult `json:"result,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	CreatedAt time.Time
-}
-
-type ScanResult struct {
-	SonarData []SonarIssue  `json:"sonar_data"`
-	Fixes     []FixProposal `json:"fixes"`
-}
-
-type SonarIssue struct {
-	File     string `json:"file"`
-	Line     int    `json:"line"`
-	Severity string `json:"severity"`
-	Message  string `json:"message"`
-	Rule     string `json:"rule"`
-}
-
-type FixProposal struct {
-	Filename        string `json:"filename"`
-	OriginalContent string `json:"original_content"`
-	NewContent      string `json:"new_content"`
-	UnifiedDiff     string `json:"unified_diff"`
+// maxUploadBytes bounds a single repo ZIP upload; spool.Stream enforces
+// this while streaming so an oversized upload is rejected mid-stream
+// instead of after it's fully landed on disk.
+const maxUploadBytes = 500 << 20 // 500 MB
+
+// scanJobTTL bounds how long a finished (or abandoned) scan job's record
+// is kept before sweepStaleJobs evicts it.
+const scanJobTTL = 24 * time.Hour
+
+type ScanResult = scanstore.ScanResult
+type SonarIssue = scanstore.SonarIssue
+type FixProposal = scanstore.FixProposal
+
+// spoolDir returns where uploads are landed before AI Core reads them
+// directly off disk, defaulting to the OS temp dir when the gateway isn't
+// configured with a dedicated volume shared with AI Core.
+func (app *App) spoolDir() string {
+	if dir := strings.TrimSpace(app.cfg.SpoolDir); dir != "" {
+		return dir
+	}
+	return os.TempDir()
 }
 
 func (app *App) handleReviewRepoAsync(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[gateway-upload] incoming request method=%s path=%s remote=%s content_length=%d", r.Method, r.URL.Path, r.RemoteAddr, r.ContentLength)
 
-	// Parse multipart form
-	err := r.ParseMultipartForm(50 << 20) // 50 MB max
+	decision, status, err := app.preAuthorize(r, r.URL.Query().Get("repo"))
 	if err != nil {
-		log.Printf("[gateway-upload] parse form failed: %v", err)
-		http.Error(w, "Failed to parse form: "+err.Error(), http.StatusBadRequest)
+		log.Printf("[gateway-upload] pre-authorize denied request: %v", err)
+		http.Error(w, "Upload not authorized", status)
 		return
 	}
 
-	file, header, err := r.FormFile("file")
+	spoolDir := app.spoolDir()
+	maxBytes := int64(maxUploadBytes)
+	if decision.TempPath != "" {
+		spoolDir = decision.TempPath
+	}
+	if decision.MaximumSize > 0 {
+		maxBytes = decision.MaximumSize
+	}
+
+	upload, err := spool.Stream(r, spoolDir, spool.Limits{MaxFileBytes: maxBytes, MaxFields: 16})
 	if err != nil {
-		log.Printf("[gateway-upload] missing file field: %v", err)
+		log.Printf("[gateway-upload] spool failed: %v", err)
+		http.Error(w, "Failed to accept upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if upload.File == nil {
+		upload.Cleanup()
 		http.Error(w, "Missing 'file' in form data", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	gitLog := r.FormValue("git_log")
-	gitDiff := r.FormValue("git_diff")
-	forceReview := r.FormValue("force_review")
+	gitLog := upload.Fields["git_log"]
+	gitDiff := upload.Fields["git_diff"]
+	forceReview := upload.Fields["force_review"]
 	log.Printf("[gateway-upload] git context received git_log_chars=%d git_diff_chars=%d force_review=%q", len(gitLog), len(gitDiff), forceReview)
 
-	// Create Job ID
 	jobID := uuid.New().String()
+	log.Printf("[gateway-upload:%s] upload accepted filename=%s size=%d spool_path=%s sha256=%s", jobID, upload.File.Name, upload.File.Size, upload.File.Path, upload.File.SHA256)
 
-	// Save zip to temp file because accessing 'file' in goroutine after handler return is unsafe
-	tempDir := os.TempDir()
-	tempPath := filepath.Join(tempDir, jobID+".zip")
-	outFile, err := os.Create(tempPath)
-	if err != nil {
-		log.Printf("[gateway-upload:%s] failed to create temp file %s: %v", jobID, tempPath, err)
-		http.Error(w, "Failed to create temp file", http.StatusInternalServerError)
-		return
-	}
-	defer outFile.Close()
-
-	_, err = io.Copy(outFile, file)
-	if err != nil {
-		log.Printf("[gateway-upload:%s] failed to persist uploaded file: %v", jobID, err)
-		http.Error(w, "Failed to save file", http.StatusInternalServerError)
-		return
-	}
-	log.Printf("[gateway-upload:%s] upload accepted filename=%s size=%d temp_path=%s", jobID, header.Filename, header.Size, tempPath)
-
-	// Initialize Job
-	job := &ScanJob{
+	job := &scanstore.ScanJob{
 		ID:        jobID,
 		Status:    "pending",
-		Logs:      []string{"Job created", fmt.Sprintf("Received file: %s (%d bytes)", header.Filename, header.Size)},
-		CreatedAt: time.Now(),
+		Logs:      []string{"Job created", fmt.Sprintf("Received file: %s (%d bytes)", upload.File.Name, upload.File.Size)},
+		SpoolPath: upload.File.Path,
+	}
+	if err := app.store.Create(job); err != nil {
+		log.Printf("[gateway-upload:%s] failed to persist job: %v", jobID, err)
+		upload.Cleanup()
+		http.Error(w, "Failed to create job", http.StatusInternalServerError)
+		return
 	}
 
-	jobsMut.Lock()
-	jobs[jobID] = job
-	jobsMut.Unlock()
-
-	// Respond immediately
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"job_id": jobID})
 	log.Printf("[gateway-upload:%s] queued async processing", jobID)
 
-	// Start Async Processing
-	go app.processScanJob(jobID, tempPath, gitLog, gitDiff, forceReview)
+	go app.processScanJob(jobID, upload.File, gitLog, gitDiff, forceReview)
 }
 
 func writeJSONError(w http.ResponseWriter, status int, message string) {
@@ -127,45 +109,39 @@ func writeJSONError(w http.ResponseWriter, status int, message string) {
 func (app *App) handleAnalyzeUnified(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[gateway-analyze-unified] incoming request method=%s path=%s remote=%s content_length=%d", r.Method, r.URL.Path, r.RemoteAddr, r.ContentLength)
 
-	err := r.ParseMultipartForm(50 << 20) // 50 MB max
+	decision, status, err := app.preAuthorize(r, r.URL.Query().Get("repo"))
 	if err != nil {
-		log.Printf("[gateway-analyze-unified] parse form failed: %v", err)
-		writeJSONError(w, http.StatusBadRequest, "Failed to parse form: "+err.Error())
+		log.Printf("[gateway-analyze-unified] pre-authorize denied request: %v", err)
+		writeJSONError(w, status, "Upload not authorized")
 		return
 	}
 
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		log.Printf("[gateway-analyze-unified] missing file field: %v", err)
-		writeJSONError(w, http.StatusBadRequest, "Missing 'file' in form data")
-		return
+	spoolDir := app.spoolDir()
+	maxBytes := int64(maxUploadBytes)
+	if decision.TempPath != "" {
+		spoolDir = decision.TempPath
+	}
+	if decision.MaximumSize > 0 {
+		maxBytes = decision.MaximumSize
 	}
-	defer file.Close()
-
-	gitDiff := r.FormValue("git_diff")
-
-	requestID := uuid.New().String()
 
-	// Save zip to temp file to avoid using file after handler returns
-	tempDir := os.TempDir()
-	tempPath := filepath.Join(tempDir, requestID+".zip")
-	outFile, err := os.Create(tempPath)
+	upload, err := spool.Stream(r, spoolDir, spool.Limits{MaxFileBytes: maxBytes, MaxFields: 16})
 	if err != nil {
-		log.Printf("[gateway-analyze-unified:%s] failed to create temp file %s: %v", requestID, tempPath, err)
-		writeJSONError(w, http.StatusInternalServerError, "Failed to create temp file")
+		log.Printf("[gateway-analyze-unified] spool failed: %v", err)
+		writeJSONError(w, http.StatusBadRequest, "Failed to accept upload: "+err.Error())
 		return
 	}
-	defer outFile.Close()
-
-	_, err = io.Copy(outFile, file)
-	if err != nil {
-		log.Printf("[gateway-analyze-unified:%s] failed to persist uploaded file: %v", requestID, err)
-		writeJSONError(w, http.StatusInternalServerError, "Failed to save file")
+	if upload.File == nil {
+		upload.Cleanup()
+		writeJSONError(w, http.StatusBadRequest, "Missing 'file' in form data")
 		return
 	}
-	log.Printf("[gateway-analyze-unified:%s] upload accepted filename=%s size=%d temp_path=%s", requestID, header.Filename, header.Size, tempPath)
+	defer upload.Cleanup()
+
+	gitDiff := upload.Fields["git_diff"]
+	requestID := uuid.New().String()
+	log.Printf("[gateway-analyze-unified:%s] upload accepted filename=%s size=%d spool_path=%s sha256=%s", requestID, upload.File.Name, upload.File.Size, upload.File.Path, upload.File.SHA256)
 
-	// Forward to AI Core
 	aiCoreURL := os.Getenv("AI_CORE_URL")
 	if strings.TrimSpace(app.cfg.AICoreURL) != "" {
 		aiCoreURL = app.cfg.AICoreURL
@@ -175,50 +151,8 @@ func (app *App) handleAnalyzeUnified(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[gateway-analyze-unified:%s] forwarding to ai_core=%s", requestID, aiCoreURL)
 
-	pr, pw := io.Pipe()
-	writer := multipart.NewWriter(pw)
-
-	go func() {
-		defer pw.Close()
-		defer writer.Close()
-
-		if gitDiff != "" {
-			if err := writer.WriteField("git_diff", gitDiff); err != nil {
-				log.Printf("[gateway-analyze-unified:%s] failed to add git_diff field: %v", requestID, err)
-			}
-		}
-
-		part, err := writer.CreateFormFile("file", "repo.zip")
-		if err != nil {
-			log.Printf("[gateway-analyze-unified:%s] failed to create multipart form field: %v", requestID, err)
-			return
-		}
-
-		zipFile, err := os.Open(tempPath)
-		if err != nil {
-			log.Printf("[gateway-analyze-unified:%s] failed to open zip for forwarding: %v", requestID, err)
-			return
-		}
-		defer zipFile.Close()
-
-		if _, err := io.Copy(part, zipFile); err != nil {
-			log.Printf("[gateway-analyze-unified:%s] failed to stream zip to multipart writer: %v", requestID, err)
-		}
-	}()
-
 	targetURL := aiCoreURL + "/api/v1/ide/analyze_unified"
-	req, err := http.NewRequest("POST", targetURL, pr)
-	if err != nil {
-		log.Printf("[gateway-analyze-unified:%s] failed to build request to ai-core: %v", requestID, err)
-		writeJSONError(w, http.StatusInternalServerError, "Failed to create request")
-		return
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("X-Request-ID", requestID)
-	log.Printf("[gateway-analyze-unified:%s] POST %s", requestID, targetURL)
-
-	client := &http.Client{Timeout: 2 * time.Minute}
-	resp, err := client.Do(req)
+	resp, err := postSpooledFile(targetURL, requestID, upload.File, map[string]string{"git_diff": gitDiff})
 	if err != nil {
 		log.Printf("[gateway-analyze-unified:%s] ai-core unreachable: %v", requestID, err)
 		writeJSONError(w, http.StatusBadGateway, "AI Core unreachable: "+err.Error())
@@ -226,9 +160,6 @@ func (app *App) handleAnalyzeUnified(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 
-	// Remove temp file after forwarding
-	_ = os.Remove(tempPath)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(resp.StatusCode)
 	if _, err := io.Copy(w, resp.Body); err != nil {
@@ -247,11 +178,8 @@ func (app *App) handleJobStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	jobID := parts[5]
 
-	jobsMut.RLock()
-	job, exists := jobs[jobID]
-	jobsMut.RUnlock()
-
-	if !exists {
+	job, err := app.store.Get(jobID)
+	if err != nil {
 		http.Error(w, "Job not found", http.StatusNotFound)
 		return
 	}
@@ -269,34 +197,61 @@ func (app *App) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 	}
 	jobID := parts[5]
 
-	jobsMut.Lock()
-	if job, exists := jobs[jobID]; exists {
-		job.Status = "cancelled"
-		job.Logs = append(job.Logs, "Job cancelled by user")
+	job, err := app.store.Get(jobID)
+	if err == nil {
+		if err := app.store.Cancel(jobID); err != nil {
+			log.Printf("[gateway-job:%s] failed to mark cancelled: %v", jobID, err)
+		}
+		_ = app.store.AppendLog(jobID, "Job cancelled by user")
+		sweepSpoolFile(jobID, job.SpoolPath)
 	}
-	jobsMut.Unlock()
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (app *App) processScanJob(jobID string, zipPath string, gitLog string, gitDiff string, forceReview string) {
-	defer os.Remove(zipPath) // Cleanup local temp zip
+// ScanStageError is a structured top-level job failure: which stage of
+// the gateway->AI-Core pipeline failed and why, formatted into the same
+// plain string Store.SetError persists but built in one place instead of
+// each call site hand-rolling its own fmt.Sprintf.
+type ScanStageError struct {
+	Stage  string
+	Reason string
+}
+
+func (e ScanStageError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Stage, e.Reason)
+}
+
+// failScanJob records a structured stage failure against jobID, used by
+// both processScanJob and pollAICoreJob instead of each hand-formatting
+// its own error string before calling Store.SetError/publishError.
+func (app *App) failScanJob(jobID string, stageErr ScanStageError) {
+	_ = app.store.SetError(jobID, stageErr.Error())
+	app.publishError(jobID, stageErr.Error())
+}
+
+func (app *App) processScanJob(jobID string, file *spool.File, gitLog string, gitDiff string, forceReview string) {
+	defer func() {
+		if job, err := app.store.Get(jobID); err == nil {
+			sweepSpoolFile(jobID, job.SpoolPath)
+		}
+	}()
 
-	// Helper to update status
 	update := func(status string, msg string) {
-		jobsMut.Lock()
-		if j, ok := jobs[jobID]; ok {
-			j.Status = status
-			if msg != "" {
-				j.Logs = append(j.Logs, msg)
-			}
+		if err := app.store.UpdateStatus(jobID, status); err != nil {
+			log.Printf("[gateway-job:%s] failed to update status to %s: %v", jobID, status, err)
+		}
+		app.publishStatus(jobID, status)
+		if msg != "" {
+			_ = app.store.AppendLog(jobID, msg)
+			app.publishLog(jobID, msg)
 		}
-		jobsMut.Unlock()
 	}
 
+	fail := func(stageErr ScanStageError) { app.failScanJob(jobID, stageErr) }
+
 	update("running", "Forwarding to AI Core...")
 
-	// 1. Forward ZIP to AI Core
 	aiCoreURL := os.Getenv("AI_CORE_URL")
 	if strings.TrimSpace(app.cfg.AICoreURL) != "" {
 		aiCoreURL = app.cfg.AICoreURL
@@ -304,64 +259,17 @@ func (app *App) processScanJob(jobID string, zipPath string, gitLog string, gitD
 	if aiCoreURL == "" {
 		aiCoreURL = "http://ai-core:3000"
 	}
-	log.Printf("[gateway-job:%s] forwarding zip=%s to ai_core=%s git_log_chars=%d git_diff_chars=%d force_review=%q", jobID, zipPath, aiCoreURL, len(gitLog), len(gitDiff), forceReview)
-
-	// Stream uploaded ZIP to AI Core to avoid buffering large files in memory.
-
-	pr, pw := io.Pipe()
-	writer := multipart.NewWriter(pw)
-
-	go func() {
-		defer pw.Close()
-		defer writer.Close()
-
-		// Always forward context fields (even if empty) so AI Core
-		// receives "" instead of None from Form(None) defaults.
-		if err := writer.WriteField("git_log", gitLog); err != nil {
-			log.Printf("[gateway-job:%s] failed to add git_log field: %v", jobID, err)
-		}
-		if err := writer.WriteField("git_diff", gitDiff); err != nil {
-			log.Printf("[gateway-job:%s] failed to add git_diff field: %v", jobID, err)
-		}
-		if err := writer.WriteField("force_review", forceReview); err != nil {
-			log.Printf("[gateway-job:%s] failed to add force_review field: %v", jobID, err)
-		}
-
-		// Add file
-		part, err := writer.CreateFormFile("file", "repo.zip")
-		if err != nil {
-			log.Printf("[gateway-job:%s] failed to create multipart form field: %v", jobID, err)
-			return
-		}
-
-		zipFile, err := os.Open(zipPath)
-		if err != nil {
-			log.Printf("[gateway-job:%s] failed to open zip for forwarding: %v", jobID, err)
-			return
-		}
-		defer zipFile.Close()
-
-		if _, err := io.Copy(part, zipFile); err != nil {
-			log.Printf("[gateway-job:%s] failed to stream zip to multipart writer: %v", jobID, err)
-		}
-	}()
+	log.Printf("[gateway-job:%s] forwarding spooled file=%s to ai_core=%s git_log_chars=%d git_diff_chars=%d force_review=%q", jobID, file.Path, aiCoreURL, len(gitLog), len(gitDiff), forceReview)
 
 	targetURL := aiCoreURL + "/api/v1/ide/review_repo_async"
-	req, err := http.NewRequest("POST", targetURL, pr)
-	if err != nil {
-		log.Printf("[gateway-job:%s] failed to build request to ai-core: %v", jobID, err)
-		update("failed", "Failed to create request: "+err.Error())
-		return
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("X-Request-ID", jobID)
-	log.Printf("[gateway-job:%s] POST %s", jobID, targetURL)
-
-	client := &http.Client{Timeout: 0} // No timeout for upload? Maybe 5 mins
-	resp, err := client.Do(req)
+	resp, err := postSpooledFile(targetURL, jobID, file, map[string]string{
+		"git_log":      gitLog,
+		"git_diff":     gitDiff,
+		"force_review": forceReview,
+	})
 	if err != nil {
 		log.Printf("[gateway-job:%s] ai-core unreachable: %v", jobID, err)
-		update("failed", "AI Core unreachable: "+err.Error())
+		fail(ScanStageError{Stage: "ai-core-enqueue", Reason: "AI Core unreachable: " + err.Error()})
 		return
 	}
 	defer resp.Body.Close()
@@ -374,87 +282,185 @@ func (app *App) processScanJob(jobID string, zipPath string, gitLog string, gitD
 		if resp.StatusCode == http.StatusNotFound {
 			log.Printf("[gateway-job:%s] ai-core returned 404 for %s (endpoint missing or app route not mounted)", jobID, targetURL)
 		}
-		update("failed", fmt.Sprintf("AI Core Error (%d): %s", resp.StatusCode, string(bodyBytes)))
+		fail(ScanStageError{Stage: "ai-core-enqueue", Reason: fmt.Sprintf("AI Core Error (%d): %s", resp.StatusCode, string(bodyBytes))})
 		return
 	}
 
 	var aiResp map[string]string
 	if err := json.NewDecoder(resp.Body).Decode(&aiResp); err != nil {
 		log.Printf("[gateway-job:%s] invalid json from ai-core enqueue: %v", jobID, err)
-		update("failed", "Invalid response from AI Core")
+		fail(ScanStageError{Stage: "ai-core-enqueue", Reason: "Invalid response from AI Core"})
 		return
 	}
 
 	aiJobID := aiResp["job_id"]
 	log.Printf("[gateway-job:%s] ai-core accepted job ai_job_id=%s", jobID, aiJobID)
+	_ = app.store.SetAIJobID(jobID, aiJobID)
 	update("running", fmt.Sprintf("AI Job Started (ID: %s). Polling...", aiJobID))
 
-	// 2. Poll AI Core for Completion
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	timeout := time.After(10 * time.Minute)
+	app.pollAICoreJob(jobID, aiCoreURL, aiJobID)
+}
 
-	for {
-		select {
-		case <-timeout:
-			update("failed", "Analysis timed out")
+// aiCoreLongPollWait is how long the gateway asks AI Core's job_status to
+// hold the request open waiting for news, replacing a fixed polling
+// interval: the gateway only wakes up (and AI Core only answers) once
+// there's actually something new to report.
+const aiCoreLongPollWait = 30 * time.Second
+
+// pollAICoreJob long-polls AI Core's job_status endpoint for aiJobID
+// until it reaches a terminal state, syncing every transition through
+// app.store (and jobevents) so a gateway restart can pick the polling
+// back up via resumeRunningJobs instead of losing track of the job.
+func (app *App) pollAICoreJob(jobID, aiCoreURL, aiJobID string) {
+	client := &http.Client{Timeout: aiCoreLongPollWait + 10*time.Second}
+	deadline := time.Now().Add(10 * time.Minute)
+	sinceLogIndex := 0
+
+	for time.Now().Before(deadline) {
+		job, err := app.store.Get(jobID)
+		if err != nil {
+			return // Job evicted locally.
+		}
+		if job.Status == "cancelled" {
 			return
-		case <-ticker.C:
-			// check status
-			statusURL := fmt.Sprintf("%s/api/v1/ide/job_status/%s", aiCoreURL, aiJobID)
-			statusReq, _ := http.NewRequest("GET", statusURL, nil)
-			statusReq.Header.Set("X-Request-ID", jobID)
-			statusResp, err := client.Do(statusReq)
-			if err != nil {
-				log.Printf("[gateway-job:%s] ai-core poll request failed: %v", jobID, err)
-				continue // retry
-			}
+		}
 
-			var data struct {
-				Status string      `json:"status"`
-				Logs   []string    `json:"logs"`
-				Result *ScanResult `json:"result,omitempty"`
-				Error  string      `json:"error,omitempty"`
-			}
-			if err := json.NewDecoder(statusResp.Body).Decode(&data); err != nil {
-				log.Printf("[gateway-job:%s] failed to decode poll response from %s status=%d error=%v", jobID, statusURL, statusResp.StatusCode, err)
-			}
-			statusResp.Body.Close()
-
-			// Sync Logs
-			jobsMut.Lock()
-			if j, ok := jobs[jobID]; ok {
-				// Naive log sync: just replace or append new ones?
-				// Let's just take the last log from AI Core if it's new
-				if len(data.Logs) > 0 {
-					lastLog := data.Logs[len(data.Logs)-1]
-					if len(j.Logs) == 0 || j.Logs[len(j.Logs)-1] != lastLog {
-						j.Logs = append(j.Logs, lastLog)
-					}
-				}
-				j.Status = data.Status
-				log.Printf("[gateway-job:%s] poll status=%s ai_job_id=%s", jobID, data.Status, aiJobID)
-
-				if data.Status == "completed" {
-					j.Result = data.Result
-					log.Printf("[gateway-job:%s] completed successfully", jobID)
-					jobsMut.Unlock()
-					return // Done
-				}
-				if data.Status == "failed" {
-					j.Error = data.Error
-					log.Printf("[gateway-job:%s] failed ai_job_id=%s error=%q", jobID, aiJobID, data.Error)
-					jobsMut.Unlock()
-					return // Done
-				}
-			} else {
-				jobsMut.Unlock()
-				return // Job killed locally?
+		statusURL := fmt.Sprintf("%s/api/v1/ide/job_status/%s?wait=%s&since=%d", aiCoreURL, aiJobID, aiCoreLongPollWait, sinceLogIndex)
+		statusReq, _ := http.NewRequest("GET", statusURL, nil)
+		statusReq.Header.Set("X-Request-ID", jobID)
+		statusResp, err := client.Do(statusReq)
+		if err != nil {
+			log.Printf("[gateway-job:%s] ai-core long-poll request failed: %v", jobID, err)
+			time.Sleep(time.Second) // avoid a tight retry loop if AI Core is down
+			continue
+		}
+
+		var data struct {
+			Status string      `json:"status"`
+			Logs   []string    `json:"logs"`
+			Result *ScanResult `json:"result,omitempty"`
+			Error  string      `json:"error,omitempty"`
+		}
+		decodeErr := json.NewDecoder(statusResp.Body).Decode(&data)
+		statusResp.Body.Close()
+		if decodeErr != nil {
+			log.Printf("[gateway-job:%s] failed to decode long-poll response from %s status=%d error=%v", jobID, statusURL, statusResp.StatusCode, decodeErr)
+			continue
+		}
+
+		for _, line := range data.Logs[min(sinceLogIndex, len(data.Logs)):] {
+			_ = app.store.AppendLog(jobID, line)
+			app.publishLog(jobID, line)
+		}
+		sinceLogIndex = len(data.Logs)
+		log.Printf("[gateway-job:%s] long-poll status=%s ai_job_id=%s since=%d", jobID, data.Status, aiJobID, sinceLogIndex)
+
+		switch data.Status {
+		case "completed", "completed_with_errors":
+			_ = app.store.SetResult(jobID, data.Result)
+			app.publishResult(jobID, data.Result)
+			failureCount := 0
+			if data.Result != nil {
+				failureCount = len(data.Result.Failures)
 			}
-			jobsMut.Unlock()
+			log.Printf("[gateway-job:%s] completed status=%s failures=%d", jobID, scanstore.ResultStatus(data.Result), failureCount)
+			return
+		case "failed":
+			app.failScanJob(jobID, ScanStageError{Stage: "ai-core-poll", Reason: data.Error})
+			log.Printf("[gateway-job:%s] failed ai_job_id=%s error=%q", jobID, aiJobID, data.Error)
+			return
+		default:
+			_ = app.store.UpdateStatus(jobID, data.Status)
+			app.publishStatus(jobID, data.Status)
 		}
 	}
+
+	app.failScanJob(jobID, ScanStageError{Stage: "ai-core-poll", Reason: "Analysis timed out"})
+}
+
+// resumeRunningJobs re-attaches to every job left "running" by a previous
+// process (crash or redeploy) and resumes polling it, instead of leaving
+// it stuck forever. Call once from main at startup, the same way
+// startTokenRefresher/startJobWorker are.
+func (app *App) resumeRunningJobs() {
+	running, err := app.store.ListRunning()
+	if err != nil {
+		log.Printf("[gateway-job] failed to list running jobs to resume: %v", err)
+		return
+	}
+
+	aiCoreURL := os.Getenv("AI_CORE_URL")
+	if strings.TrimSpace(app.cfg.AICoreURL) != "" {
+		aiCoreURL = app.cfg.AICoreURL
+	}
+	if aiCoreURL == "" {
+		aiCoreURL = "http://ai-core:3000"
+	}
+
+	for _, job := range running {
+		if job.AIJobID == "" {
+			// Never got far enough to hand off to AI Core; nothing to
+			// re-attach to, so let it fail rather than poll forever.
+			_ = app.store.SetError(job.ID, "gateway restarted before this job reached AI Core")
+			continue
+		}
+		log.Printf("[gateway-job:%s] resuming poll for ai_job_id=%s after restart", job.ID, job.AIJobID)
+		go app.pollAICoreJob(job.ID, aiCoreURL, job.AIJobID)
+	}
+}
+
+// sweepStaleJobs evicts scan jobs older than scanJobTTL, run periodically
+// alongside the token refresher (see refresher.go).
+func (app *App) sweepStaleJobs(ttl time.Duration) {
+	stale, err := app.store.ListStale(ttl)
+	if err != nil {
+		log.Printf("[gateway-job] failed to list stale jobs: %v", err)
+		return
+	}
+	for _, job := range stale {
+		sweepSpoolFile(job.ID, job.SpoolPath)
+		if err := app.store.Evict(job.ID); err != nil {
+			log.Printf("[gateway-job:%s] failed to evict stale job: %v", job.ID, err)
+		}
+	}
+}
+
+// postSpooledFile forwards a spooled upload to AI Core by reference —
+// file.path/file.name/file.size/file.sha256 plain fields instead of
+// re-encoding the file's bytes into a second multipart body — plus every
+// other field verbatim. AI Core is expected to share the spool volume and
+// read file.path directly.
+func postSpooledFile(targetURL, requestID string, file *spool.File, fields map[string]string) (*http.Response, error) {
+	values := url.Values{}
+	values.Set("file.path", file.Path)
+	values.Set("file.name", file.Name)
+	values.Set("file.size", strconv.FormatInt(file.Size, 10))
+	values.Set("file.sha256", file.SHA256)
+	for k, v := range fields {
+		values.Set(k, v)
+	}
+
+	req, err := http.NewRequest("POST", targetURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Request-ID", requestID)
+	log.Printf("[gateway-upload:%s] POST %s (spooled, no file bytes re-sent)", requestID, targetURL)
+
+	client := &http.Client{Timeout: 0}
+	return client.Do(req)
+}
+
+// sweepSpoolFile removes a job's spooled upload once it's no longer
+// needed, so repeated runs don't fill the spool volume.
+func sweepSpoolFile(jobID, path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[gateway-job:%s] failed to sweep spool file %s: %v", jobID, path, err)
+	}
 }
 
 func truncateForLog(s string, max int) string {
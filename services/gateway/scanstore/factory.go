@@ -0,0 +1,13 @@
+package scanstore
+
+import "database/sql"
+
+// New picks the configured backend: Redis when redisAddr is set (for
+// gateways running more than one replica), otherwise Postgres via db
+// (same pattern as jobs.Lease's single-instance SKIP LOCKED queue).
+func New(db *sql.DB, redisAddr string) (Store, error) {
+	if redisAddr != "" {
+		return NewRedisStore(redisAddr)
+	}
+	return NewSQLStore(db)
+}
@@ -0,0 +1,157 @@
+package scanstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisJobKeyPrefix = "scanjob:"
+	redisRunningSet   = "scanjob:running"
+	redisIndexZSet    = "scanjob:index"
+)
+
+// RedisStore is the Store implementation for gateways running more than
+// one replica: ScanJobs live in Redis instead of a single process's
+// memory, so any replica can serve handleJobStatus/handleCancelJob for a
+// job another replica created.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to addr (host:port) and returns a ready Store.
+func NewRedisStore(addr string) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("redis unreachable: %w", err)
+	}
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) Create(job *ScanJob) error {
+	ctx := context.Background()
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+
+	if err := s.save(ctx, job); err != nil {
+		return err
+	}
+	return s.client.ZAdd(ctx, redisIndexZSet, redis.Z{Score: float64(job.CreatedAt.Unix()), Member: job.ID}).Err()
+}
+
+func (s *RedisStore) Get(id string) (*ScanJob, error) {
+	ctx := context.Background()
+	raw, err := s.client.Get(ctx, redisJobKeyPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("job %q not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	job := &ScanJob{}
+	if err := json.Unmarshal(raw, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *RedisStore) UpdateStatus(id, status string) error {
+	return s.mutate(id, func(job *ScanJob) { job.Status = status })
+}
+
+func (s *RedisStore) AppendLog(id, line string) error {
+	return s.mutate(id, func(job *ScanJob) { job.Logs = append(job.Logs, line) })
+}
+
+func (s *RedisStore) SetAIJobID(id, aiJobID string) error {
+	return s.mutate(id, func(job *ScanJob) { job.AIJobID = aiJobID })
+}
+
+func (s *RedisStore) SetResult(id string, result *ScanResult) error {
+	return s.mutate(id, func(job *ScanJob) {
+		job.Status = ResultStatus(result)
+		job.Result = result
+	})
+}
+
+func (s *RedisStore) SetError(id string, errMsg string) error {
+	return s.mutate(id, func(job *ScanJob) {
+		job.Status = "failed"
+		job.Error = errMsg
+	})
+}
+
+func (s *RedisStore) Cancel(id string) error {
+	return s.mutate(id, func(job *ScanJob) { job.Status = "cancelled" })
+}
+
+func (s *RedisStore) ListRunning() ([]*ScanJob, error) {
+	ctx := context.Background()
+	ids, err := s.client.SMembers(ctx, redisRunningSet).Result()
+	if err != nil {
+		return nil, err
+	}
+	return s.getMany(ids)
+}
+
+func (s *RedisStore) ListStale(ttl time.Duration) ([]*ScanJob, error) {
+	ctx := context.Background()
+	ids, err := s.client.ZRangeByScore(ctx, redisIndexZSet, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", time.Now().Add(-ttl).Unix()),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return s.getMany(ids)
+}
+
+func (s *RedisStore) Evict(id string) error {
+	ctx := context.Background()
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, redisJobKeyPrefix+id)
+	pipe.ZRem(ctx, redisIndexZSet, id)
+	pipe.SRem(ctx, redisRunningSet, id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStore) getMany(ids []string) ([]*ScanJob, error) {
+	jobs := make([]*ScanJob, 0, len(ids))
+	for _, id := range ids {
+		job, err := s.Get(id)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *RedisStore) save(ctx context.Context, job *ScanJob) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, redisJobKeyPrefix+job.ID, raw, 0).Err(); err != nil {
+		return err
+	}
+	if job.Status == "running" {
+		return s.client.SAdd(ctx, redisRunningSet, job.ID).Err()
+	}
+	return s.client.SRem(ctx, redisRunningSet, job.ID).Err()
+}
+
+func (s *RedisStore) mutate(id string, fn func(job *ScanJob)) error {
+	job, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	fn(job)
+	job.UpdatedAt = time.Now()
+	return s.save(context.Background(), job)
+}
@@ -0,0 +1,164 @@
+package scanstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// SQLStore persists ScanJobs in Postgres, reusing the same *sql.DB the
+// rest of the gateway already holds (see db.go's initDB) instead of
+// opening a second connection pool.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore ensures the scan_jobs table exists and returns a Store
+// backed by db.
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	_, err := db.Exec(`
+		create table if not exists scan_jobs (
+			id text primary key,
+			status text not null default 'pending',
+			logs jsonb not null default '[]',
+			result jsonb,
+			error text not null default '',
+			ai_job_id text not null default '',
+			spool_path text not null default '',
+			created_at timestamptz default now(),
+			updated_at timestamptz default now()
+		);
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLStore{db: db}, nil
+}
+
+func (s *SQLStore) Create(job *ScanJob) error {
+	logsJSON, err := json.Marshal(job.Logs)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		insert into scan_jobs (id, status, logs, spool_path)
+		values ($1, $2, $3, $4)
+	`, job.ID, job.Status, logsJSON, job.SpoolPath)
+	return err
+}
+
+func (s *SQLStore) Get(id string) (*ScanJob, error) {
+	row := s.db.QueryRow(`
+		select id, status, logs, result, error, ai_job_id, spool_path, created_at, updated_at
+		from scan_jobs where id = $1
+	`, id)
+	return scanRow(row)
+}
+
+func (s *SQLStore) UpdateStatus(id, status string) error {
+	_, err := s.db.Exec(`update scan_jobs set status = $1, updated_at = now() where id = $2`, status, id)
+	return err
+}
+
+func (s *SQLStore) AppendLog(id, line string) error {
+	_, err := s.db.Exec(`
+		update scan_jobs set logs = logs || to_jsonb($1::text), updated_at = now() where id = $2
+	`, line, id)
+	return err
+}
+
+func (s *SQLStore) SetAIJobID(id, aiJobID string) error {
+	_, err := s.db.Exec(`update scan_jobs set ai_job_id = $1, updated_at = now() where id = $2`, aiJobID, id)
+	return err
+}
+
+func (s *SQLStore) SetResult(id string, result *ScanResult) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		update scan_jobs set status = $1, result = $2, updated_at = now() where id = $3
+	`, ResultStatus(result), resultJSON, id)
+	return err
+}
+
+func (s *SQLStore) SetError(id string, errMsg string) error {
+	_, err := s.db.Exec(`
+		update scan_jobs set status = 'failed', error = $1, updated_at = now() where id = $2
+	`, errMsg, id)
+	return err
+}
+
+func (s *SQLStore) Cancel(id string) error {
+	_, err := s.db.Exec(`
+		update scan_jobs set status = 'cancelled', updated_at = now() where id = $1
+	`, id)
+	return err
+}
+
+func (s *SQLStore) ListRunning() ([]*ScanJob, error) {
+	rows, err := s.db.Query(`
+		select id, status, logs, result, error, ai_job_id, spool_path, created_at, updated_at
+		from scan_jobs where status = 'running'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+func (s *SQLStore) ListStale(ttl time.Duration) ([]*ScanJob, error) {
+	rows, err := s.db.Query(`
+		select id, status, logs, result, error, ai_job_id, spool_path, created_at, updated_at
+		from scan_jobs where created_at < now() - $1::interval
+	`, ttl.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+func (s *SQLStore) Evict(id string) error {
+	_, err := s.db.Exec(`delete from scan_jobs where id = $1`, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRow(row rowScanner) (*ScanJob, error) {
+	job := &ScanJob{}
+	var logsJSON, resultJSON []byte
+	err := row.Scan(&job.ID, &job.Status, &logsJSON, &resultJSON, &job.Error, &job.AIJobID, &job.SpoolPath, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(logsJSON) > 0 {
+		if err := json.Unmarshal(logsJSON, &job.Logs); err != nil {
+			return nil, err
+		}
+	}
+	if len(resultJSON) > 0 {
+		job.Result = &ScanResult{}
+		if err := json.Unmarshal(resultJSON, job.Result); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+func scanRows(rows *sql.Rows) ([]*ScanJob, error) {
+	var jobs []*ScanJob
+	for rows.Next() {
+		job, err := scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
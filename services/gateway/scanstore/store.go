@@ -0,0 +1,94 @@
+// Package scanstore persists the state handleReviewRepoAsync's async scan
+// jobs move through, behind a Store interface so the gateway can run
+// against either Postgres or Redis without scan_api.go caring which.
+// Before this package existed that state lived in an in-memory map, which
+// meant a redeploy silently dropped every in-flight job.
+package scanstore
+
+import "time"
+
+// ScanJob mirrors the shape the VS Code extension polls for, plus the
+// bookkeeping (AIJobID, SpoolPath) a restarted gateway needs to re-attach
+// to a job instead of losing it.
+type ScanJob struct {
+	ID        string      `json:"job_id"`
+	Status    string      `json:"status"` // pending, running, completed, failed, cancelled
+	Logs      []string    `json:"logs"`
+	Result    *ScanResult `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	AIJobID   string      `json:"ai_job_id,omitempty"`
+	SpoolPath string      `json:"-"`
+	CreatedAt time.Time   `json:"-"`
+	UpdatedAt time.Time   `json:"-"`
+}
+
+type ScanResult struct {
+	SonarData []SonarIssue  `json:"sonar_data"`
+	Fixes     []FixProposal `json:"fixes"`
+
+	// Failures lists per-file problems that didn't abort the whole scan,
+	// e.g. one file the fix-generator couldn't parse while the rest
+	// completed fine. PartialSuccess is true whenever Fixes is non-empty
+	// despite Failures being non-empty, so ResultStatus can tell a fully
+	// failed scan from one an IDE client should still render fixes for.
+	Failures       []FixFailure `json:"failures,omitempty"`
+	PartialSuccess bool         `json:"partial_success,omitempty"`
+}
+
+// FixFailure reports why one file didn't get a fix, mirroring the
+// structured per-item failure shape of indices/reasons (rather than one
+// opaque top-level message) so IDE clients can render per-file
+// diagnostics instead of a single toast.
+type FixFailure struct {
+	Filename  string `json:"filename"`
+	Stage     string `json:"stage"`
+	Reason    string `json:"reason"`
+	Retryable bool   `json:"retryable"`
+}
+
+// ResultStatus derives the terminal job status a completed ScanResult
+// should be stored under: "completed_with_errors" when some files failed
+// but the scan still produced usable fixes, "completed" otherwise. A scan
+// with no successful fixes at all is reported via SetError instead, so
+// ResultStatus never needs to return "failed".
+func ResultStatus(result *ScanResult) string {
+	if result != nil && result.PartialSuccess && len(result.Failures) > 0 {
+		return "completed_with_errors"
+	}
+	return "completed"
+}
+
+type SonarIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Rule     string `json:"rule"`
+}
+
+type FixProposal struct {
+	Filename        string `json:"filename"`
+	OriginalContent string `json:"original_content"`
+	NewContent      string `json:"new_content"`
+	UnifiedDiff     string `json:"unified_diff"`
+}
+
+// Store is every operation processScanJob and its HTTP handlers need,
+// factored out so a gateway restart can resume against whichever backend
+// is configured instead of losing jobs held only in process memory.
+type Store interface {
+	Create(job *ScanJob) error
+	Get(id string) (*ScanJob, error)
+	UpdateStatus(id, status string) error
+	AppendLog(id, line string) error
+	SetAIJobID(id, aiJobID string) error
+	SetResult(id string, result *ScanResult) error
+	SetError(id string, errMsg string) error
+	Cancel(id string) error
+	// ListRunning returns every job still marked "running", for the
+	// gateway to re-attach its ai_job_id and resume polling after restart.
+	ListRunning() ([]*ScanJob, error)
+	// ListStale returns jobs created before ttl ago, for eviction.
+	ListStale(ttl time.Duration) ([]*ScanJob, error)
+	Evict(id string) error
+}
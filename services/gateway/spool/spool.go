@@ -0,0 +1,146 @@
+// Package spool streams an incoming multipart upload straight to disk
+// instead of buffering it in memory, and hands back a path the caller can
+// forward to a downstream service by reference instead of re-encoding the
+// bytes into a second multipart body. This mirrors the accelerated-upload
+// pattern GitLab Workhorse uses in front of its Rails/Go backends.
+package spool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// File describes the single "file" part Stream spooled to disk.
+type File struct {
+	Path   string
+	Name   string
+	Size   int64
+	SHA256 string
+}
+
+// Upload is the result of streaming one multipart request: the spooled
+// file (if the request had one) plus every other field, verbatim.
+type Upload struct {
+	File   *File
+	Fields map[string]string
+}
+
+// Limits bounds what Stream will accept before it aborts the upload.
+type Limits struct {
+	MaxFileBytes int64 // 0 means unbounded
+	MaxFields    int   // 0 means unbounded
+}
+
+// Cleanup removes the spooled file, if any. Callers must call this once
+// the file is no longer needed (job completion, cancellation, or a
+// rejected request) so the spool directory doesn't fill up.
+func (u *Upload) Cleanup() error {
+	if u == nil || u.File == nil {
+		return nil
+	}
+	return os.Remove(u.File.Path)
+}
+
+// Stream reads r's multipart body part-by-part, writing the "file" part
+// straight into dir under a random name and recording every other part as
+// a plain form field. Limits are enforced while streaming so an
+// oversized/abusive upload is rejected before it's fully received rather
+// than after.
+func Stream(r *http.Request, dir string, limits Limits) (*Upload, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("not a multipart request: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to prepare spool dir: %w", err)
+	}
+
+	upload := &Upload{Fields: make(map[string]string)}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			upload.Cleanup()
+			return nil, fmt.Errorf("failed to read multipart body: %w", err)
+		}
+
+		if part.FormName() == "file" {
+			file, err := spoolPart(part, dir, limits.MaxFileBytes)
+			part.Close()
+			if err != nil {
+				upload.Cleanup()
+				return nil, err
+			}
+			upload.File = file
+			continue
+		}
+
+		if limits.MaxFields > 0 && len(upload.Fields) >= limits.MaxFields {
+			part.Close()
+			upload.Cleanup()
+			return nil, fmt.Errorf("too many form fields (limit %d)", limits.MaxFields)
+		}
+
+		value, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			upload.Cleanup()
+			return nil, fmt.Errorf("failed to read field %q: %w", part.FormName(), err)
+		}
+		upload.Fields[part.FormName()] = string(value)
+	}
+
+	return upload, nil
+}
+
+func spoolPart(part *multipart.Part, dir string, maxBytes int64) (*File, error) {
+	originalName := part.FileName()
+	if originalName == "" {
+		originalName = part.FormName()
+	}
+
+	spoolPath := filepath.Join(dir, uuid.New().String()+".spool")
+	out, err := os.Create(spoolPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	writer := io.MultiWriter(out, hasher)
+
+	var reader io.Reader = part
+	limited := maxBytes > 0
+	if limited {
+		reader = io.LimitReader(part, maxBytes+1)
+	}
+
+	written, err := io.Copy(writer, reader)
+	if err != nil {
+		os.Remove(spoolPath)
+		return nil, fmt.Errorf("failed to spool upload: %w", err)
+	}
+	if limited && written > maxBytes {
+		os.Remove(spoolPath)
+		return nil, fmt.Errorf("upload exceeds the %d byte limit", maxBytes)
+	}
+
+	return &File{
+		Path:   spoolPath,
+		Name:   originalName,
+		Size:   written,
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+	}, nil
+}
@@ -25,7 +25,7 @@ type RuntimeGitStatus struct {
 
 func (a *App) handleMe(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(ctxKeyUserID{}).(int64)
-	user, err := getUserByID(a.db, userID)
+	user, err := getUserByID(a.db, a.kek, userID)
 	if err != nil {
 		http.Error(w, "user not found", http.StatusUnauthorized)
 		return
@@ -33,7 +33,8 @@ func (a *App) handleMe(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"id":               user.ID,
-		"github_id":        user.GitHubID,
+		"remote_source_id": user.RemoteSourceID,
+		"remote_user_id":   user.RemoteUserID,
 		"login":            user.Login,
 		"github_install":   strings.TrimSpace(a.cfg.GitHubAppInstallURL),
 		"backend_url":      strings.TrimSpace(a.cfg.BackendURL),
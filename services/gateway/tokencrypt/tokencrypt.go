@@ -0,0 +1,137 @@
+// Package tokencrypt provides envelope encryption for secrets persisted to
+// Postgres (OAuth access/refresh tokens). Each row gets its own
+// data-encryption key (DEK); the DEK itself is wrapped with a
+// key-encryption key (KEK) sourced from env or a KMS, so compromising the
+// database alone never exposes plaintext tokens.
+package tokencrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Envelope is what gets persisted alongside a ciphertext column: the
+// per-row DEK (wrapped under the KEK) and the nonce used to seal the
+// plaintext under that DEK.
+type Envelope struct {
+	WrappedDEK []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// LoadKEK reads a base64-encoded 256-bit key-encryption key from the named
+// environment variable. In production this env var is expected to be
+// populated from a KMS-backed secret, not a literal key.
+func LoadKEK(envVar string) ([]byte, error) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return nil, fmt.Errorf("tokencrypt: %s is not set", envVar)
+	}
+	kek, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("tokencrypt: %s is not valid base64: %w", envVar, err)
+	}
+	if len(kek) != 32 {
+		return nil, fmt.Errorf("tokencrypt: %s must decode to 32 bytes, got %d", envVar, len(kek))
+	}
+	return kek, nil
+}
+
+// Seal generates a fresh per-row DEK, encrypts plaintext under it, and
+// wraps the DEK under kek. An empty plaintext still produces a valid
+// envelope so callers don't need to special-case unset refresh tokens.
+func Seal(kek []byte, plaintext string) (*Envelope, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+
+	nonce, ciphertext, err := encrypt(dek, []byte(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedDEK, err := wrapDEK(kek, dek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{WrappedDEK: wrappedDEK, Nonce: nonce, Ciphertext: ciphertext}, nil
+}
+
+// Open reverses Seal: unwrap the row's DEK under kek, then decrypt the
+// ciphertext under the recovered DEK.
+func Open(kek []byte, env *Envelope) (string, error) {
+	if env == nil {
+		return "", errors.New("tokencrypt: nil envelope")
+	}
+	dek, err := unwrapDEK(kek, env.WrappedDEK)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := decrypt(dek, env.Nonce, env.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func wrapDEK(kek, dek []byte) ([]byte, error) {
+	nonce, ciphertext, err := encrypt(kek, dek)
+	if err != nil {
+		return nil, err
+	}
+	// Wrapped form is nonce||ciphertext since the DEK's wrapping nonce has
+	// nowhere else to live in the users table schema.
+	return append(nonce, ciphertext...), nil
+}
+
+func unwrapDEK(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, errors.New("tokencrypt: wrapped DEK shorter than nonce size")
+	}
+	nonce, ciphertext := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func encrypt(key, plaintext []byte) (nonce, ciphertext []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	ciphertext = gcm.Seal(nil, nonce, plaintext, nil)
+	return nonce, ciphertext, nil
+}
+
+func decrypt(key, nonce, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
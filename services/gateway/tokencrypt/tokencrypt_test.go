@@ -0,0 +1,58 @@
+package tokencrypt
+
+import "testing"
+
+func testKEK() []byte {
+	return make([]byte, 32) // zero key is fine for a round-trip test
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	kek := testKEK()
+	env, err := Seal(kek, "gho_sometoken")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	got, err := Open(kek, env)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got != "gho_sometoken" {
+		t.Fatalf("Open returned %q, want %q", got, "gho_sometoken")
+	}
+}
+
+func TestSealOpenEmptyPlaintext(t *testing.T) {
+	kek := testKEK()
+	env, err := Seal(kek, "")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	got, err := Open(kek, env)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("Open returned %q, want empty string", got)
+	}
+}
+
+func TestOpenWrongKEKFails(t *testing.T) {
+	kek := testKEK()
+	env, err := Seal(kek, "gho_sometoken")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	wrongKEK := make([]byte, 32)
+	wrongKEK[0] = 1
+	if _, err := Open(wrongKEK, env); err == nil {
+		t.Fatal("expected Open with the wrong KEK to fail, got nil error")
+	}
+}
+
+func TestOpenNilEnvelope(t *testing.T) {
+	if _, err := Open(testKEK(), nil); err == nil {
+		t.Fatal("expected Open(nil) to return an error")
+	}
+}
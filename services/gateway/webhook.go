@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+
+	"git-app-gateway/analyzer"
+)
+
+// handleGitHubWebhook receives delivery events from a GitHub App
+// installation (pull_request, push, check_suite) and drives the analyzer
+// pipeline against them, publishing results back as a Check Run rather
+// than the PR review comments the legacy mock flow posted.
+func (a *App) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !verifyGitHubSignature(a.cfg.GitHubWebhookSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), body)
+	if err != nil {
+		http.Error(w, "unrecognized event", http.StatusBadRequest)
+		return
+	}
+
+	switch e := event.(type) {
+	case *github.CheckSuiteEvent:
+		a.handleCheckSuiteEvent(r.Context(), e)
+	case *github.PullRequestEvent:
+		a.handlePullRequestEvent(r.Context(), e)
+	case *github.PushEvent:
+		a.handlePushEvent(r.Context(), e)
+	default:
+		log.Printf("[gateway-webhook] ignoring unhandled event type %s", github.WebHookType(r))
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verifyGitHubSignature checks the sha256 HMAC GitHub sends in
+// X-Hub-Signature-256, mirroring gitsource/helpers.go's verifyHMACSHA256
+// for providers with their own webhook secret per installation.
+func verifyGitHubSignature(secret string, body []byte, header string) bool {
+	if secret == "" || header == "" {
+		return false
+	}
+	const prefix = "sha256="
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(header, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func (a *App) handleCheckSuiteEvent(ctx context.Context, e *github.CheckSuiteEvent) {
+	if e.GetAction() != "requested" && e.GetAction() != "rerequested" {
+		return
+	}
+	a.runCheckForHeadSHA(ctx, e.GetInstallation().GetID(), e.GetRepo(), e.GetCheckSuite().GetHeadSHA())
+}
+
+func (a *App) handlePullRequestEvent(ctx context.Context, e *github.PullRequestEvent) {
+	switch e.GetAction() {
+	case "opened", "synchronize", "reopened":
+	default:
+		return
+	}
+	headSHA := e.GetPullRequest().GetHead().GetSHA()
+	installationID := e.GetInstallation().GetID()
+	repo := e.GetRepo()
+	a.runCheckForHeadSHA(ctx, installationID, repo, headSHA)
+	a.enqueueScanJobForRef(ctx, installationID, repo.GetOwner().GetLogin(), repo.GetName(), repo.GetFullName(), headSHA)
+}
+
+// handlePushEvent fetches the pushed ref's tree and runs it through the
+// full ScanJob pipeline, closing the loop from webhook delivery to
+// processScanJob the same way a manual VS Code upload would: a push to
+// main needs the same treatment a PR gets, not just the lighter Check
+// Run annotations runCheckForHeadSHA produces.
+func (a *App) handlePushEvent(ctx context.Context, e *github.PushEvent) {
+	if e.GetDeleted() {
+		return
+	}
+	sha := e.GetAfter()
+	if sha == "" || sha == strictZeroSHA {
+		return
+	}
+	repo := e.GetRepo()
+	a.enqueueScanJobForRef(ctx, e.GetInstallation().GetID(), repo.GetOwner().GetLogin(), repo.GetName(), repo.GetFullName(), sha)
+}
+
+// strictZeroSHA is what GitHub sends as the "after" SHA on a branch
+// deletion push; GetDeleted() already filters these, but we keep this as
+// a defensive second check since some forges omit the deleted flag.
+const strictZeroSHA = "0000000000000000000000000000000000000000"
+
+// runCheckForHeadSHA fetches the commit's diff under the installation's
+// own token, runs it through the analyzer pipeline, and publishes the
+// result as a Check Run via publishCheckRun.
+func (a *App) runCheckForHeadSHA(ctx context.Context, installationID int64, repo *github.Repository, headSHA string) {
+	if installationID == 0 || repo == nil || headSHA == "" {
+		return
+	}
+
+	client, err := a.installationClient(ctx, installationID)
+	if err != nil {
+		log.Printf("[gateway-webhook] installation client error: %v", err)
+		return
+	}
+
+	checkRun, _, err := client.Checks.CreateCheckRun(ctx, repo.GetOwner().GetLogin(), repo.GetName(), github.CreateCheckRunOptions{
+		Name:    "testpilot-review",
+		HeadSHA: headSHA,
+		Status:  github.String("in_progress"),
+	})
+	if err != nil {
+		log.Printf("[gateway-webhook] failed to create check run: %v", err)
+		return
+	}
+
+	diff, err := fetchCommitDiff(ctx, client, repo.GetOwner().GetLogin(), repo.GetName(), headSHA)
+	if err != nil {
+		log.Printf("[gateway-webhook] failed to fetch diff for %s@%s: %v", repo.GetFullName(), headSHA, err)
+		a.completeCheckRun(ctx, client, repo, checkRun.GetID(), nil, err)
+		return
+	}
+
+	pipeline := a.analyzerPipeline()
+	issues, _ := pipeline.Run(ctx, analyzer.Request{
+		Repo:      repo.GetFullName(),
+		CommitSHA: headSHA,
+		Diff:      diff,
+	})
+	issues = analyzer.Filter(issues, analyzer.ParseDiffScope(diff))
+
+	a.completeCheckRun(ctx, client, repo, checkRun.GetID(), issues, nil)
+}
+
+func fetchCommitDiff(ctx context.Context, client *github.Client, owner, repoName, sha string) (string, error) {
+	diff, _, err := client.Repositories.GetCommitRaw(ctx, owner, repoName, sha, github.RawOptions{Type: github.Diff})
+	return diff, err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/google/uuid"
+
+	"git-app-gateway/scanstore"
+	"git-app-gateway/spool"
+)
+
+// enqueueScanJobForRef downloads owner/name's tree at ref as a tarball
+// using the installation's own token and feeds it into the same
+// ScanJob/processScanJob pipeline handleReviewRepoAsync uses for a manual
+// upload, so a push or pull_request webhook produces IDE-grade scan
+// results (logs, fixes, SSE progress) without anyone running the VS Code
+// extension at all. Takes owner/name/fullName as plain strings rather
+// than *github.Repository since PushEvent and PullRequestEvent expose
+// their repo as different go-github types.
+//
+// The installation-token minting (installationClient/appClient),
+// per-installation persistence (upsertInstallation), and webhook
+// signature verification this relies on already landed in
+// handleGitHubAppCallback/app_install.go and verifyGitHubSignature; this
+// file only adds the auto-enqueue half of that flow on top.
+func (a *App) enqueueScanJobForRef(ctx context.Context, installationID int64, owner, name, fullName, ref string) {
+	if installationID == 0 || owner == "" || name == "" || ref == "" {
+		return
+	}
+
+	client, err := a.installationClient(ctx, installationID)
+	if err != nil {
+		log.Printf("[gateway-webhook] installation client error: %v", err)
+		return
+	}
+
+	file, err := a.downloadRepoArchive(ctx, client, owner, name, ref)
+	if err != nil {
+		log.Printf("[gateway-webhook] failed to download archive for %s@%s: %v", fullName, ref, err)
+		return
+	}
+
+	jobID := uuid.New().String()
+	job := &scanstore.ScanJob{
+		ID:        jobID,
+		Status:    "pending",
+		Logs:      []string{"Job created", fmt.Sprintf("Webhook triggered scan of %s@%s", fullName, ref)},
+		SpoolPath: file.Path,
+	}
+	if err := a.store.Create(job); err != nil {
+		log.Printf("[gateway-webhook:%s] failed to persist job: %v", jobID, err)
+		os.Remove(file.Path)
+		return
+	}
+
+	log.Printf("[gateway-webhook:%s] queued scan of %s@%s from webhook", jobID, fullName, ref)
+	go a.processScanJob(jobID, file, "", "", "true")
+}
+
+// downloadRepoArchive lands repo's tarball at ref on disk at app.spoolDir,
+// computing the same Path/Name/Size/SHA256 fields spool.Stream fills in
+// for a manual upload so the rest of the pipeline can't tell the
+// difference between an uploaded ZIP and a webhook-fetched tarball.
+func (a *App) downloadRepoArchive(ctx context.Context, client *github.Client, owner, repoName, ref string) (*spool.File, error) {
+	archiveURL, _, err := client.Repositories.GetArchiveLink(ctx, owner, repoName, github.Tarball, &github.RepositoryContentGetOptions{Ref: ref}, true)
+	if err != nil {
+		return nil, fmt.Errorf("resolve archive link: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download archive: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download archive: unexpected status %d", resp.StatusCode)
+	}
+
+	name := fmt.Sprintf("%s-%s.tar.gz", repoName, ref)
+	path := filepath.Join(a.spoolDir(), uuid.New().String()+"-"+name)
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	written, err := io.Copy(out, io.TeeReader(resp.Body, hasher))
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("write archive: %w", err)
+	}
+
+	return &spool.File{
+		Path:   path,
+		Name:   name,
+		Size:   written,
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// initDB opens the Postgres connection shared by every internal/ package
+// that's given a *sql.DB; each package owns creating its own tables
+// (auth.EnsureUsersTable, jobs.EnsureQueueTables, agent.EnsureConfigTable,
+// authz.EnsureTables) rather than this file knowing their schemas.
+func initDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
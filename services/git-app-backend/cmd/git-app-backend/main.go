@@ -0,0 +1,107 @@
+// Command git-app-backend is the GitHub App server: OAuth login, webhook
+// ingress/dispatch, the agent extension point, and the dead-letter admin
+// API, wired together from the internal/ packages.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"git-app-backend/internal/agent"
+	"git-app-backend/internal/auth"
+	"git-app-backend/internal/authz"
+	"git-app-backend/internal/config"
+	"git-app-backend/internal/githubx"
+	"git-app-backend/internal/httpx"
+	"git-app-backend/internal/jobs"
+	"git-app-backend/internal/webhooks"
+)
+
+// webhookWorkerConcurrency is how many goroutines poll the jobs queue at
+// once; configurable via env since a busy installation fleet wants more
+// parallelism than a single-tenant demo deploy.
+func webhookWorkerConcurrency() int {
+	if raw := os.Getenv("WEBHOOK_WORKER_CONCURRENCY"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n > 0 {
+			return int(n)
+		}
+	}
+	return 4
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to a TOML config file (see internal/config for the schema)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("config load failed: %v", err)
+	}
+
+	db, err := initDB(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("db init failed: %v", err)
+	}
+	defer db.Close()
+
+	store := config.NewStore(cfg)
+	config.Watch(store, *configPath)
+
+	if err := auth.EnsureUsersTable(db); err != nil {
+		log.Fatalf("users table init failed: %v", err)
+	}
+	if err := jobs.EnsureQueueTables(db); err != nil {
+		log.Fatalf("webhook queue table init failed: %v", err)
+	}
+	if err := agent.EnsureConfigTable(db); err != nil {
+		log.Fatalf("agent config table init failed: %v", err)
+	}
+	if err := authz.EnsureTables(db); err != nil {
+		log.Fatalf("authz table init failed: %v", err)
+	}
+
+	clients := githubx.AppClientProvider{AppID: cfg.GitHub.AppID, PrivateKey: cfg.GitHub.PrivateKey}
+	webhookHandler := webhooks.Handler{DB: db, Config: store, Clients: clients}
+	jobs.StartWorkers(context.Background(), db, webhookHandler.Dispatcher(), webhookWorkerConcurrency())
+
+	authHandler := auth.Handler{DB: db, Config: store}
+	adminHandler := jobs.AdminHandler{DB: db}
+	agentConfigHandler := agent.ConfigHandler{DB: db}
+
+	staticDir := httpx.ResolveStaticDir()
+	mux := http.NewServeMux()
+
+	// API routes (these take precedence over catch-all)
+	mux.HandleFunc("/auth/login", authHandler.Login)
+	mux.HandleFunc("/auth/install", authHandler.InstallStart)
+	mux.HandleFunc("/auth/callback", authHandler.Callback)
+	mux.HandleFunc("/webhooks/github", webhookHandler.Ingress)
+	mux.HandleFunc("/api/orgs", authHandler.Middleware(authHandler.ListOrgs))
+	mux.HandleFunc("/api/repos", authHandler.Middleware(authHandler.ListRepos))
+	mux.HandleFunc("/api/webhooks/deliveries", authHandler.Middleware(adminHandler.List))
+	mux.HandleFunc("/api/webhooks/deliveries/", authHandler.Middleware(adminHandler.Replay))
+	mux.HandleFunc("/api/agents/config", authHandler.Middleware(agentConfigHandler.SetConfig))
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	// Serve static frontend files (catch-all must be last)
+	mux.HandleFunc("/", httpx.SPAHandler(staticDir))
+
+	server := &http.Server{
+		Addr:              store.Get().Server.Addr,
+		Handler:           httpx.CORS(func() []string { return store.Get().Server.CORSOrigins }, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("git-app-backend listening on %s", server.Addr)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
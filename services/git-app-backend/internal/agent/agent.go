@@ -0,0 +1,252 @@
+// Package agent is the pluggable analyzer extension point: given a repo
+// and a head SHA, produce file changes, a PR/review summary, and Check
+// Run annotations. MockRunner is the original hackathon-demo behavior;
+// ExecRunner shells out to a user-configured binary.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// RepoContext is everything a Runner needs to look at a repo and
+// propose changes, without reaching into webhook/config internals -
+// this is the extension point a user-configured binary (ExecRunner) runs
+// with, no more access than a well-behaved pre-commit hook gets.
+type RepoContext struct {
+	Client         *github.Client
+	Owner          string
+	Repo           string
+	InstallationID int64
+	BaseSHA        string
+	ChangedFiles   []string
+	Workspace      string // scratch checkout dir, unique per run
+}
+
+// FileChange is one file a Runner wants written (created or updated) in
+// the branch it opens a PR from.
+type FileChange struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// CheckAnnotation mirrors the subset of a GitHub Check Run annotation a
+// Runner can usefully produce; package checks is what actually turns
+// these into `client.Checks.UpdateCheckRun` calls.
+type CheckAnnotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"level"` // notice, warning, failure
+	Message   string `json:"message"`
+}
+
+// Result is what Run produces: a set of file changes to land on a
+// branch, the PR (or review) copy to use, and optional annotations for a
+// Check Run.
+type Result struct {
+	Changes     []FileChange      `json:"changes"`
+	PRTitle     string            `json:"pr_title"`
+	PRBody      string            `json:"pr_body"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// Runner is the extension point: given a RepoContext, produce a Result.
+// Callers own turning that into an actual branch/PR/Check Run; a runner
+// never calls the GitHub API to write anything itself.
+type Runner interface {
+	Run(ctx context.Context, repoCtx RepoContext) (*Result, error)
+}
+
+// MockRunner is the original hackathon-demo behavior: a single canned
+// report file and PR/review body, generated in-process with no clone.
+// It's the default runner when nothing else is configured.
+type MockRunner struct{}
+
+func (MockRunner) Run(ctx context.Context, repoCtx RepoContext) (*Result, error) {
+	return &Result{
+		Changes: []FileChange{
+			{Path: "ai_optimization_report.md", Content: reportContent(repoCtx.Owner, repoCtx.Repo)},
+		},
+		PRTitle: "AI Optimization Suggestions (Mock)",
+		PRBody:  prBody(repoCtx.Owner, repoCtx.Repo),
+	}, nil
+}
+
+// ExecRunner shells out to a user-configured binary against a real
+// checkout, exactly like a pre-commit/linter contract: clone the repo at
+// BaseSHA into a temp worktree using an installation token, run the
+// binary against that worktree, and parse its stdout as a JSON Result
+// manifest.
+type ExecRunner struct {
+	// Binary is the path to the user-configured analyzer/fixer
+	// executable, invoked as `Binary <worktree-dir>`.
+	Binary string
+	// Timeout bounds how long the binary is allowed to run.
+	Timeout time.Duration
+}
+
+func (r ExecRunner) Run(ctx context.Context, repoCtx RepoContext) (*Result, error) {
+	if r.Binary == "" {
+		return nil, fmt.Errorf("exec runner: no binary configured")
+	}
+
+	worktree := repoCtx.Workspace
+	if worktree == "" {
+		dir, err := os.MkdirTemp("", "testpilot-agent-*")
+		if err != nil {
+			return nil, fmt.Errorf("exec runner: create worktree: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		worktree = dir
+	}
+
+	if err := r.cloneAtSHA(ctx, repoCtx, worktree); err != nil {
+		return nil, fmt.Errorf("exec runner: clone: %w", err)
+	}
+
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, r.Binary, worktree)
+	cmd.Dir = worktree
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("exec runner: %s exited: %w (stderr: %s)", r.Binary, err, truncateOutput(stderr.String(), 2000))
+	}
+
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("exec runner: invalid manifest from %s: %w", r.Binary, err)
+	}
+	return &result, nil
+}
+
+// cloneAtSHA shallow-clones owner/repo at BaseSHA into dir using a
+// short-lived installation token, the same credential shortLivedGitToken
+// mints in the gateway service for the IDE's git askpass flow.
+func (r ExecRunner) cloneAtSHA(ctx context.Context, repoCtx RepoContext, dir string) error {
+	installToken, _, err := repoCtx.Client.Apps.CreateInstallationToken(ctx, repoCtx.InstallationID, nil)
+	if err != nil {
+		return fmt.Errorf("mint installation token: %w", err)
+	}
+
+	cloneURL := fmt.Sprintf("https://x-access-token:%s@github.com/%s/%s.git", installToken.GetToken(), repoCtx.Owner, repoCtx.Repo)
+	cloneCmd := exec.CommandContext(ctx, "git", "clone", "--quiet", cloneURL, dir)
+	if out, err := cloneCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone: %w (%s)", err, truncateOutput(string(out), 500))
+	}
+
+	if repoCtx.BaseSHA != "" {
+		checkoutCmd := exec.CommandContext(ctx, "git", "-C", dir, "checkout", "--quiet", repoCtx.BaseSHA)
+		if out, err := checkoutCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git checkout %s: %w (%s)", repoCtx.BaseSHA, err, truncateOutput(string(out), 500))
+		}
+	}
+	return nil
+}
+
+func truncateOutput(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "...(truncated)"
+}
+
+// ApplyResult lands result.Changes on a fresh branch off BaseSHA and
+// opens a PR from it.
+func ApplyResult(ctx context.Context, client *github.Client, owner, repo string, result *Result) error {
+	if len(result.Changes) == 0 {
+		return nil
+	}
+
+	mainRef, _, err := client.Git.GetRef(ctx, owner, repo, "refs/heads/main")
+	if err != nil {
+		return err
+	}
+
+	branchName := fmt.Sprintf("ai-fix-%d", time.Now().Unix())
+	newRef := &github.Reference{
+		Ref:    github.String("refs/heads/" + branchName),
+		Object: &github.GitObject{SHA: mainRef.Object.SHA},
+	}
+	if _, _, err := client.Git.CreateRef(ctx, owner, repo, newRef); err != nil {
+		return err
+	}
+
+	for _, change := range result.Changes {
+		if err := putFileOnBranch(ctx, client, owner, repo, branchName, change); err != nil {
+			return err
+		}
+	}
+
+	pr := &github.NewPullRequest{
+		Title: github.String(result.PRTitle),
+		Head:  github.String(branchName),
+		Base:  github.String("main"),
+		Body:  github.String(result.PRBody),
+	}
+	_, _, err = client.PullRequests.Create(ctx, owner, repo, pr)
+	return err
+}
+
+func putFileOnBranch(ctx context.Context, client *github.Client, owner, repo, branch string, change FileChange) error {
+	message := github.String(fmt.Sprintf("chore: update %s", filepath.Base(change.Path)))
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, change.Path, &github.RepositoryContentGetOptions{Ref: branch})
+	if err == nil && file != nil && file.SHA != nil {
+		_, _, err = client.Repositories.UpdateFile(ctx, owner, repo, change.Path, &github.RepositoryContentFileOptions{
+			Message: message,
+			Content: []byte(change.Content),
+			SHA:     file.SHA,
+			Branch:  github.String(branch),
+		})
+		return err
+	}
+	_, _, err = client.Repositories.CreateFile(ctx, owner, repo, change.Path, &github.RepositoryContentFileOptions{
+		Message: message,
+		Content: []byte(change.Content),
+		Branch:  github.String(branch),
+	})
+	return err
+}
+
+func reportContent(owner, repo string) string {
+	payload := map[string]any{
+		"repo":   fmt.Sprintf("%s/%s", owner, repo),
+		"score":  94,
+		"wins":   []string{"Reduced cold start time", "Improved query batching", "De-duplicated cache keys"},
+		"notes":  "This is a mocked AI report generated instantly for hackathon demo purposes.",
+		"impact": "~18% faster requests and ~22% lower DB load (simulated)",
+	}
+	b, _ := json.MarshalIndent(payload, "", "  ")
+
+	var buf bytes.Buffer
+	buf.WriteString("# AI Optimization Report (Mock)\n\n")
+	buf.WriteString("**Generated:** " + time.Now().Format(time.RFC1123) + "\n\n")
+	buf.WriteString("## Highlights\n")
+	buf.WriteString("- \U0001F680 Hot path micro-optimizations\n")
+	buf.WriteString("- \U0001F9E0 Smarter batching and caching\n")
+	buf.WriteString("- ⚡ Lowered latency and CPU\n\n")
+	buf.WriteString("## Summary (JSON)\n")
+	buf.WriteString("```json\n" + string(b) + "\n```\n")
+	buf.WriteString("\n> This is demo content. Replace with real AI output later.\n")
+	return buf.String()
+}
+
+func prBody(owner, repo string) string {
+	return fmt.Sprintf("\n## \U0001F916 AI Agent Summary (Mock)\n\nI analyzed **%s/%s** and found quick wins that can be safely automated.\n\n### \U0001F50D What Changed\n- Added `ai_optimization_report.md` with optimization highlights\n- Mocked performance analysis summary with JSON report\n\n### \U0001F9EA Estimated Impact (Simulated)\n- **Latency:** -18%%\n- **DB Load:** -22%%\n- **Cold Start:** -35%%\n\n### ✅ Next Steps\n- Review the report\n- Merge if acceptable\n- Replace mock generator with real AI pipeline\n\n> This PR was generated instantly for hackathon demo purposes.\n", owner, repo)
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"git-app-backend/internal/auth"
+	"git-app-backend/internal/authz"
+	"git-app-backend/internal/config"
+	"git-app-backend/internal/httpx"
+)
+
+// DBConfig is which Runner an installation wants for a given event type,
+// persisted so it survives a redeploy and so different installations can
+// point at different analyzers without a code change.
+type DBConfig struct {
+	InstallationID int64  `json:"installation_id"`
+	EventType      string `json:"event_type"`
+	Runner         string `json:"runner"`      // "mock" or "exec"
+	BinaryPath     string `json:"binary_path"` // only meaningful when Runner == "exec"
+}
+
+func EnsureConfigTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		create table if not exists agent_configs (
+			installation_id bigint not null,
+			event_type text not null,
+			runner text not null default 'mock',
+			binary_path text not null default '',
+			updated_at timestamptz not null default now(),
+			primary key (installation_id, event_type)
+		);
+	`)
+	return err
+}
+
+// ResolveRunner resolves the Runner registered for installationID and
+// eventType. An installation that never called POST /api/agents/config
+// has no row, so it falls back to whichever runner the matched
+// [[trigger]]/.testpilot.yml resolved to (fallback) - and if even that
+// resolves to nothing in particular, MockRunner, so an unconfigured
+// installation keeps the original demo behavior either way.
+func ResolveRunner(db *sql.DB, installationID int64, eventType string, fallback Runner) (Runner, error) {
+	var runner, binaryPath string
+	err := db.QueryRow(`
+		select runner, binary_path from agent_configs where installation_id = $1 and event_type = $2
+	`, installationID, eventType).Scan(&runner, &binaryPath)
+	if err == sql.ErrNoRows {
+		if fallback != nil {
+			return fallback, nil
+		}
+		return MockRunner{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch runner {
+	case "exec":
+		return ExecRunner{Binary: binaryPath}, nil
+	default:
+		return MockRunner{}, nil
+	}
+}
+
+// ResolveNamed looks up name among the config file's [[agents.runner]]
+// definitions (set via a [[trigger]]'s agent field or a repo's
+// .testpilot.yml), returning MockRunner when name is empty or unknown.
+func ResolveNamed(name string, defs []config.AgentRunnerDef) Runner {
+	if name == "" {
+		return MockRunner{}
+	}
+	for _, r := range defs {
+		if r.Name != name {
+			continue
+		}
+		if r.Type == "exec" {
+			return ExecRunner{Binary: r.BinaryPath}
+		}
+		return MockRunner{}
+	}
+	return MockRunner{}
+}
+
+func UpsertConfig(db *sql.DB, cfg DBConfig) error {
+	_, err := db.Exec(`
+		insert into agent_configs (installation_id, event_type, runner, binary_path, updated_at)
+		values ($1, $2, $3, $4, now())
+		on conflict (installation_id, event_type)
+		do update set runner = excluded.runner, binary_path = excluded.binary_path, updated_at = now()
+	`, cfg.InstallationID, cfg.EventType, cfg.Runner, cfg.BinaryPath)
+	return err
+}
+
+// ConfigHandler backs POST /api/agents/config, letting a repo owner
+// point push/pull_request handling at their own analyzer binary instead
+// of the built-in MockRunner.
+type ConfigHandler struct {
+	DB *sql.DB
+
+	// IsInstallationAdmin checks whether the caller may administer a
+	// given installation_id; overridable in tests, defaults to
+	// authz.IsInstallationAdmin against DB when nil.
+	IsInstallationAdmin func(db *sql.DB, userID, installationID int64) (bool, error)
+}
+
+func (h ConfigHandler) isInstallationAdmin(userID, installationID int64) (bool, error) {
+	check := h.IsInstallationAdmin
+	if check == nil {
+		check = authz.IsInstallationAdmin
+	}
+	return check(h.DB, userID, installationID)
+}
+
+func (h ConfigHandler) SetConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var cfg DBConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, "invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+	if cfg.InstallationID == 0 || cfg.EventType == "" {
+		http.Error(w, "installation_id and event_type are required", http.StatusBadRequest)
+		return
+	}
+	if cfg.Runner == "" {
+		cfg.Runner = "mock"
+	}
+
+	userID, ok := auth.UserID(r.Context())
+	if !ok {
+		http.Error(w, "missing user", http.StatusUnauthorized)
+		return
+	}
+	allowed, err := h.isInstallationAdmin(userID, cfg.InstallationID)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "not authorized for this installation", http.StatusForbidden)
+		return
+	}
+
+	if err := UpsertConfig(h.DB, cfg); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"saved": true})
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"git-app-backend/internal/auth"
+	"git-app-backend/internal/config"
+)
+
+func TestConfigHandlerSetConfig_RejectsNonAdmin(t *testing.T) {
+	h := ConfigHandler{
+		IsInstallationAdmin: func(db *sql.DB, userID, installationID int64) (bool, error) {
+			// Only user 1 administers installation 42.
+			return userID == 1 && installationID == 42, nil
+		},
+	}
+
+	body := strings.NewReader(`{"installation_id": 42, "event_type": "push", "runner": "exec", "binary_path": "/bin/sh"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/agents/config", body)
+	req = req.WithContext(auth.WithUserID(req.Context(), 2)) // attacker, not an admin of 42
+
+	rec := httptest.NewRecorder()
+	h.SetConfig(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin caller, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestConfigHandlerSetConfig_RejectsUnauthenticated(t *testing.T) {
+	h := ConfigHandler{
+		IsInstallationAdmin: func(db *sql.DB, userID, installationID int64) (bool, error) {
+			t.Fatal("IsInstallationAdmin should not be consulted when there is no authenticated user")
+			return false, nil
+		},
+	}
+
+	body := strings.NewReader(`{"installation_id": 42, "event_type": "push"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/agents/config", body)
+
+	rec := httptest.NewRecorder()
+	h.SetConfig(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with no authenticated user, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestResolveNamed_UnknownNameFallsBackToMock(t *testing.T) {
+	runner := ResolveNamed("does-not-exist", nil)
+	if _, ok := runner.(MockRunner); !ok {
+		t.Fatalf("expected MockRunner fallback for an unknown name, got %T", runner)
+	}
+}
+
+func TestResolveNamed_MatchesExecRunner(t *testing.T) {
+	defs := []config.AgentRunnerDef{{Name: "linter", Type: "exec", BinaryPath: "/usr/local/bin/linter"}}
+	runner := ResolveNamed("linter", defs)
+	exec, ok := runner.(ExecRunner)
+	if !ok {
+		t.Fatalf("expected ExecRunner, got %T", runner)
+	}
+	if exec.Binary != "/usr/local/bin/linter" {
+		t.Fatalf("expected binary path to carry through, got %q", exec.Binary)
+	}
+}
@@ -0,0 +1,254 @@
+// Package auth is the user-facing side of the GitHub App: the OAuth
+// login flow that issues our own session JWTs, the middleware that
+// verifies them, and the repo listing a logged-in user can see.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-github/v61/github"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+
+	"git-app-backend/internal/config"
+	"git-app-backend/internal/httpx"
+)
+
+type ctxKeyUserID struct{}
+
+// UserID extracts the authenticated user's ID set by Middleware.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(ctxKeyUserID{}).(int64)
+	return id, ok
+}
+
+// WithUserID attaches userID the same way Middleware does, for handlers
+// composed outside the normal mux chain and for tests that need an
+// authenticated context without going through a real JWT.
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, ctxKeyUserID{}, userID)
+}
+
+// Handler backs the OAuth login flow and the session-scoped API routes.
+type Handler struct {
+	DB     *sql.DB
+	Config *config.Store
+}
+
+func (h Handler) Login(w http.ResponseWriter, r *http.Request) {
+	state, err := randomState()
+	if err != nil {
+		http.Error(w, "state error", http.StatusInternalServerError)
+		return
+	}
+	redirectURL := h.oauthConfig().AuthCodeURL(state, oauth2.AccessTypeOnline)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth_state",
+		Value:    state,
+		HttpOnly: true,
+		Path:     "/",
+		MaxAge:   300,
+	})
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+func (h Handler) Callback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	code := r.URL.Query().Get("code")
+	stored, err := r.Cookie("oauth_state")
+	if err != nil || stored.Value != state {
+		http.Error(w, "invalid state", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.oauthConfig().Exchange(context.Background(), code)
+	if err != nil {
+		http.Error(w, "token exchange failed", http.StatusInternalServerError)
+		return
+	}
+
+	client := github.NewClient(h.oauthConfig().Client(context.Background(), token))
+	user, _, err := client.Users.Get(context.Background(), "")
+	if err != nil {
+		http.Error(w, "user fetch failed", http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := upsertUser(h.DB, user.GetID(), user.GetLogin(), token.AccessToken)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	jwtToken, err := h.issueJWT(userID)
+	if err != nil {
+		http.Error(w, "jwt error", http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to the same domain (Render backend serves frontend)
+	redirect := fmt.Sprintf("%s/auth/workspace?token=%s", h.Config.Get().Server.BackendURL, jwtToken)
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// InstallStart redirects a logged-in user to the GitHub App's install
+// page (cfg.GitHub.AppInstallURL). GitHub's install flow doesn't exchange
+// a code back through us the way OAuth login does - the "installation"
+// webhook event is what tells webhooks.Handler an install completed - so
+// there's no state to round-trip here.
+func (h Handler) InstallStart(w http.ResponseWriter, r *http.Request) {
+	installURL := strings.TrimSpace(h.Config.Get().GitHub.AppInstallURL)
+	if installURL == "" {
+		http.Error(w, "missing install url", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, installURL, http.StatusFound)
+}
+
+// ListOrgs backs GET /api/orgs for the logged-in user, so the onboarding
+// flow can suggest which org to install the App into.
+func (h Handler) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserID(r.Context())
+	if !ok {
+		http.Error(w, "missing user", http.StatusUnauthorized)
+		return
+	}
+	user, err := getUserByID(h.DB, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusUnauthorized)
+		return
+	}
+
+	token := &oauth2.Token{AccessToken: user.AccessToken}
+	client := github.NewClient(oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(token)))
+	orgs, _, err := client.Organizations.List(context.Background(), "", &github.ListOptions{PerPage: 100})
+	if err != nil {
+		http.Error(w, "github error", http.StatusBadGateway)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(orgs))
+	for _, org := range orgs {
+		response = append(response, map[string]any{
+			"id":         org.GetID(),
+			"login":      org.GetLogin(),
+			"type":       org.GetType(),
+			"avatar_url": org.GetAvatarURL(),
+		})
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"orgs": response})
+}
+
+// ListRepos backs GET /api/repos for the logged-in user.
+func (h Handler) ListRepos(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserID(r.Context())
+	if !ok {
+		http.Error(w, "missing user", http.StatusUnauthorized)
+		return
+	}
+	user, err := getUserByID(h.DB, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusUnauthorized)
+		return
+	}
+
+	token := &oauth2.Token{AccessToken: user.AccessToken}
+	client := github.NewClient(oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(token)))
+	repos, _, err := client.Repositories.List(context.Background(), "", &github.RepositoryListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+		Visibility:  "all",
+	})
+	if err != nil {
+		http.Error(w, "github error", http.StatusBadGateway)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(repos))
+	for _, repo := range repos {
+		response = append(response, map[string]any{
+			"id":        repo.GetID(),
+			"name":      repo.GetName(),
+			"full_name": repo.GetFullName(),
+			"private":   repo.GetPrivate(),
+			"url":       repo.GetHTMLURL(),
+		})
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"repos":       response,
+		"install_url": strings.TrimSpace(h.Config.Get().GitHub.AppInstallURL),
+	})
+}
+
+// Middleware verifies the Authorization: Bearer JWT issued by Callback
+// and attaches the user ID to the request context for UserID to read.
+func (h Handler) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		parts := strings.SplitN(authorization, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		token, err := jwt.Parse(parts[1], func(t *jwt.Token) (any, error) {
+			return h.Config.JWTKey(), nil
+		})
+		if err != nil || !token.Valid {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		sub, ok := claims["sub"].(float64)
+		if !ok {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ctxKeyUserID{}, int64(sub))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (h Handler) issueJWT(userID int64) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"exp": time.Now().Add(24 * time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(h.Config.JWTKey())
+}
+
+func (h Handler) oauthConfig() *oauth2.Config {
+	cfg := h.Config.Get()
+	return &oauth2.Config{
+		ClientID:     cfg.GitHub.ClientID,
+		ClientSecret: cfg.GitHub.ClientSecret,
+		RedirectURL:  cfg.GitHub.OAuthRedirect,
+		Scopes:       []string{"repo", "read:user"},
+		Endpoint:     githuboauth.Endpoint,
+	}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import "database/sql"
+
+// User is a platform user authenticated via GitHub OAuth, keyed by their
+// GitHub account id so a repeat login updates the same row instead of
+// creating a duplicate.
+type User struct {
+	ID          int64
+	GitHubID    int64
+	Login       string
+	AccessToken string
+}
+
+// EnsureUsersTable creates the users table if it doesn't already exist.
+func EnsureUsersTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		create table if not exists users (
+			id bigserial primary key,
+			github_id bigint not null unique,
+			login text not null,
+			access_token text not null default '',
+			created_at timestamptz not null default now(),
+			updated_at timestamptz not null default now()
+		);
+	`)
+	return err
+}
+
+// upsertUser records or refreshes a user's login and access token on
+// every OAuth callback, keyed by their stable GitHub account id.
+func upsertUser(db *sql.DB, githubID int64, login, accessToken string) (int64, error) {
+	var id int64
+	err := db.QueryRow(`
+		insert into users (github_id, login, access_token, updated_at)
+		values ($1, $2, $3, now())
+		on conflict (github_id) do update set
+			login = excluded.login,
+			access_token = excluded.access_token,
+			updated_at = now()
+		returning id
+	`, githubID, login, accessToken).Scan(&id)
+	return id, err
+}
+
+func getUserByID(db *sql.DB, id int64) (*User, error) {
+	u := &User{}
+	err := db.QueryRow(`
+		select id, github_id, login, access_token from users where id = $1
+	`, id).Scan(&u.ID, &u.GitHubID, &u.Login, &u.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UserIDForGitHubID resolves a platform user id from their GitHub account
+// id, so the "installation" webhook event can grant installation_admins
+// to whoever GitHub says installed the App without making them
+// re-authenticate first.
+func UserIDForGitHubID(db *sql.DB, githubID int64) (int64, error) {
+	var id int64
+	err := db.QueryRow(`select id from users where github_id = $1`, githubID).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,72 @@
+// Package authz tracks which platform users are allowed to administer
+// which GitHub App installations, so endpoints that act on an
+// installation_id supplied by the caller (agent config, webhook replay)
+// can check the caller actually owns that installation instead of
+// trusting whatever ID is in the request body.
+package authz
+
+import "database/sql"
+
+// EnsureTables creates the installation_admins table if it doesn't
+// already exist.
+func EnsureTables(db *sql.DB) error {
+	_, err := db.Exec(`
+		create table if not exists installation_admins (
+			installation_id bigint not null,
+			user_id bigint not null,
+			created_at timestamptz not null default now(),
+			primary key (installation_id, user_id)
+		);
+	`)
+	return err
+}
+
+// IsInstallationAdmin reports whether userID may administer
+// installationID. Fails closed: an installation with no recorded admins
+// denies everyone rather than defaulting open.
+func IsInstallationAdmin(db *sql.DB, userID, installationID int64) (bool, error) {
+	var allowed bool
+	err := db.QueryRow(`
+		select exists(
+			select 1 from installation_admins where installation_id = $1 and user_id = $2
+		)
+	`, installationID, userID).Scan(&allowed)
+	if err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
+
+// GrantInstallationAdmin records that userID administers installationID,
+// idempotently - called off GitHub's "installation" webhook event for
+// whoever GitHub reports as the installer, since that's the only
+// point in the flow where we know which platform user to trust with it.
+func GrantInstallationAdmin(db *sql.DB, userID, installationID int64) error {
+	_, err := db.Exec(`
+		insert into installation_admins (installation_id, user_id)
+		values ($1, $2)
+		on conflict (installation_id, user_id) do nothing
+	`, installationID, userID)
+	return err
+}
+
+// InstallationIDsForUser lists every installation userID administers, so
+// callers can scope a listing (e.g. webhook deliveries) to just the
+// caller's own installations instead of every tenant's.
+func InstallationIDsForUser(db *sql.DB, userID int64) ([]int64, error) {
+	rows, err := db.Query(`select installation_id from installation_admins where user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,122 @@
+// Package checks turns an agent.Result into a GitHub Check Run instead
+// of a PR review comment, so feedback shows up as inline annotations in
+// the PR's Checks tab.
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+
+	"git-app-backend/internal/agent"
+)
+
+const runName = "TestPilot Agent"
+
+// maxAnnotationsPerUpdate is GitHub's hard cap on annotations per
+// Checks.UpdateCheckRun call; larger sets get chunked across multiple
+// update calls instead of being rejected outright.
+const maxAnnotationsPerUpdate = 50
+
+// Publisher manages one Check Run's lifecycle against a given client.
+type Publisher struct {
+	Client *github.Client
+}
+
+func New(client *github.Client) Publisher {
+	return Publisher{Client: client}
+}
+
+// Start creates an in_progress Check Run against headSHA, with a
+// "Re-run" action that surfaces check_run.rerequested, and returns its
+// ID so Finish can report back to the same run once the agent completes.
+func (p Publisher) Start(ctx context.Context, owner, repo, headSHA string) (int64, error) {
+	run, _, err := p.Client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:    runName,
+		HeadSHA: headSHA,
+		Status:  github.String("in_progress"),
+		Actions: []*github.CheckRunAction{
+			{Label: "Re-run", Description: "Re-run the TestPilot agent", Identifier: "rerun"},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return run.GetID(), nil
+}
+
+// Finish reports the agent's annotations and marks the Check Run
+// complete with conclusion, chunking annotations across multiple update
+// calls since GitHub rejects more than maxAnnotationsPerUpdate per
+// request. The final batch carries the completed status/conclusion;
+// earlier batches stay in_progress.
+func (p Publisher) Finish(ctx context.Context, owner, repo string, checkRunID int64, conclusion, summary string, annotations []agent.CheckAnnotation) error {
+	batches := chunkAnnotations(annotations, maxAnnotationsPerUpdate)
+	if len(batches) == 0 {
+		batches = [][]agent.CheckAnnotation{nil}
+	}
+
+	for i, batch := range batches {
+		opts := github.UpdateCheckRunOptions{
+			Name: runName,
+			Output: &github.CheckRunOutput{
+				Title:       github.String(runName),
+				Summary:     github.String(summary),
+				Annotations: toGitHubAnnotations(batch),
+			},
+		}
+		if i == len(batches)-1 {
+			opts.Status = github.String("completed")
+			opts.Conclusion = github.String(conclusion)
+		} else {
+			opts.Status = github.String("in_progress")
+		}
+		if _, _, err := p.Client.Checks.UpdateCheckRun(ctx, owner, repo, checkRunID, opts); err != nil {
+			return fmt.Errorf("update check run (batch %d/%d): %w", i+1, len(batches), err)
+		}
+	}
+	return nil
+}
+
+// ConclusionFor derives a Check Run conclusion from the agent's own
+// annotations rather than trusting a separately-reported status string -
+// the same "single source of truth" approach scanstore.ResultStatus
+// uses for the gateway service's scan jobs.
+func ConclusionFor(annotations []agent.CheckAnnotation) string {
+	for _, a := range annotations {
+		if a.Level == "failure" {
+			return "failure"
+		}
+	}
+	return "success"
+}
+
+func chunkAnnotations(annotations []agent.CheckAnnotation, size int) [][]agent.CheckAnnotation {
+	if len(annotations) == 0 {
+		return nil
+	}
+	var batches [][]agent.CheckAnnotation
+	for size < len(annotations) {
+		annotations, batches = annotations[size:], append(batches, annotations[:size:size])
+	}
+	return append(batches, annotations)
+}
+
+func toGitHubAnnotations(annotations []agent.CheckAnnotation) []*github.CheckRunAnnotation {
+	out := make([]*github.CheckRunAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		level := a.Level
+		if level == "" {
+			level = "notice"
+		}
+		out = append(out, &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.StartLine),
+			EndLine:         github.Int(a.EndLine),
+			AnnotationLevel: github.String(level),
+			Message:         github.String(a.Message),
+		})
+	}
+	return out
+}
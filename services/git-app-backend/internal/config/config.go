@@ -0,0 +1,233 @@
+// Package config is the TOML-backed, hot-reloadable configuration for
+// git-app-backend: server/github/database/agents sections plus repeated
+// [[trigger]] blocks, with secrets overridable via env vars.
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ServerConfig is the [server] TOML section.
+type ServerConfig struct {
+	Addr        string   `toml:"addr"`
+	JWTSecret   string   `toml:"jwt_secret"`
+	FrontendURL string   `toml:"frontend_url"`
+	BackendURL  string   `toml:"backend_url"`
+	CORSOrigins []string `toml:"cors_origins"`
+}
+
+// GitHubConfig is the [github] TOML section.
+type GitHubConfig struct {
+	ClientID      string `toml:"client_id"`
+	ClientSecret  string `toml:"client_secret"`
+	OAuthRedirect string `toml:"oauth_redirect"`
+	WebhookSecret string `toml:"webhook_secret"`
+	AppID         int64  `toml:"app_id"`
+	PrivateKey    string `toml:"private_key"`
+	AppInstallURL string `toml:"app_install_url"`
+}
+
+// DatabaseConfig is the [database] TOML section.
+type DatabaseConfig struct {
+	URL string `toml:"url"`
+}
+
+// AgentRunnerDef names a reusable agent.Runner so [[trigger]] blocks can
+// reference it by name instead of repeating a binary path everywhere.
+type AgentRunnerDef struct {
+	Name       string `toml:"name"`
+	Type       string `toml:"type"` // "mock" or "exec"
+	BinaryPath string `toml:"binary_path"`
+}
+
+// AgentsConfig is the [agents] TOML section.
+type AgentsConfig struct {
+	DefaultRunner     string           `toml:"default_runner"`
+	WorkerConcurrency int              `toml:"worker_concurrency"`
+	Runners           []AgentRunnerDef `toml:"runner"`
+}
+
+// TriggerConfig is one [[trigger]] block: it decides whether a push or
+// pull_request event on a given owner/repo should run an agent at all,
+// and if so which one. Match is an owner/repo glob (path.Match syntax,
+// e.g. "myorg/*"); Branches and Paths are optional allow-lists.
+type TriggerConfig struct {
+	Match    string   `toml:"match"`
+	Branches []string `toml:"branches"`
+	Paths    []string `toml:"paths"`
+	Agent    string   `toml:"agent"`
+	Enabled  bool     `toml:"enabled"`
+}
+
+// Config is the whole config.toml file. Env vars listed in
+// applyEnvOverrides take precedence over whatever the file says, so
+// secrets never have to be committed to it.
+type Config struct {
+	Server   ServerConfig    `toml:"server"`
+	GitHub   GitHubConfig    `toml:"github"`
+	Database DatabaseConfig  `toml:"database"`
+	Agents   AgentsConfig    `toml:"agents"`
+	Triggers []TriggerConfig `toml:"trigger"`
+}
+
+// Load reads path (if it exists - a deploy with only env vars and no
+// file is still valid), applies env var overrides, then fills in
+// defaults for anything still unset.
+func Load(path string) (Config, error) {
+	var cfg Config
+	if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			if _, err := toml.DecodeFile(path, &cfg); err != nil {
+				return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return Config{}, fmt.Errorf("stat config %s: %w", path, err)
+		}
+	}
+	applyEnvOverrides(&cfg)
+	applyConfigDefaults(&cfg)
+	return cfg, nil
+}
+
+// applyEnvOverrides lets secrets keep living outside the config file
+// (env vars, Render/Heroku-style secret stores) while everything else -
+// triggers, worker concurrency, CORS origins - lives in the file.
+func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("GITHUB_CLIENT_ID"); v != "" {
+		cfg.GitHub.ClientID = v
+	}
+	if v := os.Getenv("GITHUB_CLIENT_SECRET"); v != "" {
+		cfg.GitHub.ClientSecret = v
+	}
+	if v := os.Getenv("GITHUB_OAUTH_REDIRECT"); v != "" {
+		cfg.GitHub.OAuthRedirect = v
+	}
+	if v := os.Getenv("GITHUB_WEBHOOK_SECRET"); v != "" {
+		cfg.GitHub.WebhookSecret = v
+	}
+	if v := os.Getenv("GITHUB_APP_ID"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.GitHub.AppID = n
+		} else {
+			log.Printf("ignoring invalid GITHUB_APP_ID: %v", err)
+		}
+	}
+	if v := os.Getenv("GITHUB_APP_PRIVATE_KEY"); v != "" {
+		cfg.GitHub.PrivateKey = v
+	}
+	if v := os.Getenv("GITHUB_APP_INSTALL_URL"); v != "" {
+		cfg.GitHub.AppInstallURL = v
+	}
+	if v := os.Getenv("JWT_SECRET"); v != "" {
+		cfg.Server.JWTSecret = v
+	}
+	if v := os.Getenv("DATABASE_URL"); v != "" {
+		cfg.Database.URL = v
+	}
+	if v := os.Getenv("FRONTEND_URL"); v != "" {
+		cfg.Server.FrontendURL = v
+	}
+	if v := os.Getenv("BACKEND_URL"); v != "" {
+		cfg.Server.BackendURL = v
+	}
+}
+
+func applyConfigDefaults(cfg *Config) {
+	if cfg.Server.Addr == "" {
+		cfg.Server.Addr = ":8001"
+	}
+	if len(cfg.Server.CORSOrigins) == 0 {
+		cfg.Server.CORSOrigins = []string{"*"}
+	}
+	if cfg.Agents.DefaultRunner == "" {
+		cfg.Agents.DefaultRunner = "mock"
+	}
+	if cfg.Agents.WorkerConcurrency == 0 {
+		cfg.Agents.WorkerConcurrency = 4
+	}
+}
+
+// Store holds the live config behind a RWMutex so a hot reload can swap
+// it out from under in-flight requests without tearing a reader's view.
+type Store struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+func NewStore(cfg Config) *Store {
+	return &Store{cfg: cfg}
+}
+
+// Get returns a snapshot of the active config. Callers get a copy, so a
+// reload swapping the store mid-request can't tear a caller's read.
+func (s *Store) Get() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+func (s *Store) Set(cfg Config) {
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mu.Unlock()
+}
+
+// JWTKey is a convenience accessor for the one field read on every
+// authenticated request.
+func (s *Store) JWTKey() []byte {
+	return []byte(s.Get().Server.JWTSecret)
+}
+
+// Watch reloads and atomically swaps the store's config whenever path
+// changes on disk, so triggers, agent selection, and CORS origins can be
+// retuned without a restart. A failed reload just logs and keeps serving
+// the last good config.
+func Watch(store *Store, path string) {
+	if path == "" {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("config watch disabled: %v", err)
+		return
+	}
+	if err := watcher.Add(path); err != nil {
+		log.Printf("config watch disabled for %s: %v", path, err)
+		watcher.Close()
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				cfg, err := Load(path)
+				if err != nil {
+					log.Printf("config reload failed, keeping previous config: %v", err)
+					continue
+				}
+				store.Set(cfg)
+				log.Printf("config reloaded from %s", path)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("config watch error: %v", err)
+			}
+		}
+	}()
+}
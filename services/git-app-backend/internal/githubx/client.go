@@ -0,0 +1,34 @@
+// Package githubx is the GitHub API access layer: minting
+// installation-scoped clients and reading repo-root override files.
+// ClientProvider exists as an interface specifically so webhooks/agent
+// tests can inject a fake instead of a real ghinstallation transport.
+package githubx
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v61/github"
+)
+
+// ClientProvider builds a GitHub client scoped to one App installation.
+type ClientProvider interface {
+	NewInstallationClient(installationID int64) (*github.Client, error)
+}
+
+// AppClientProvider is the production ClientProvider, backed by a GitHub
+// App ID and private key.
+type AppClientProvider struct {
+	AppID      int64
+	PrivateKey string
+}
+
+func (p AppClientProvider) NewInstallationClient(installationID int64) (*github.Client, error) {
+	key := []byte(strings.ReplaceAll(p.PrivateKey, "\\n", "\n"))
+	tr, err := ghinstallation.New(http.DefaultTransport, p.AppID, installationID, key)
+	if err != nil {
+		return nil, err
+	}
+	return github.NewClient(&http.Client{Transport: tr}), nil
+}
@@ -0,0 +1,46 @@
+package githubx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v61/github"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoOverride is the per-installation knobs a repo owner can set in a
+// .testpilot.yml at their repo root, giving them the same agent/enabled
+// controls a [[trigger]] block gives an operator, without needing access
+// to the service's own config.toml.
+type RepoOverride struct {
+	Agent   string `yaml:"agent"`
+	Enabled *bool  `yaml:"enabled"`
+}
+
+// LoadRepoOverride fetches and parses .testpilot.yml at ref. A missing
+// file is not an error - it just means the repo hasn't opted into any
+// overrides - so callers get (nil, nil) in that case.
+func LoadRepoOverride(ctx context.Context, client *github.Client, owner, repo, ref string) (*RepoOverride, error) {
+	file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, ".testpilot.yml", &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decode .testpilot.yml: %w", err)
+	}
+
+	var override RepoOverride
+	if err := yaml.Unmarshal([]byte(content), &override); err != nil {
+		return nil, fmt.Errorf("parse .testpilot.yml: %w", err)
+	}
+	return &override, nil
+}
@@ -0,0 +1,89 @@
+// Package httpx holds the HTTP plumbing that doesn't belong to any one
+// feature: CORS, the SPA static-file handler, and the JSON response
+// helper every handler package uses.
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// WriteJSON is the single place every handler serializes a response, so
+// the Content-Type header and encoding behavior stay consistent.
+func WriteJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// CORS reads the allowed origin list from originsFn on every request
+// (rather than once at startup) so a config hot reload can retune it
+// without a restart.
+func CORS(originsFn func() []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := originFor(originsFn(), r.Header.Get("Origin"))
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func originFor(allowed []string, requestOrigin string) string {
+	for _, o := range allowed {
+		if o == "*" {
+			return "*"
+		}
+		if o == requestOrigin {
+			return requestOrigin
+		}
+	}
+	return ""
+}
+
+// ResolveStaticDir finds the Next.js static export, trying the directory
+// next to the running executable first and falling back to paths that
+// work when running from a source checkout.
+func ResolveStaticDir() string {
+	if exePath, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exePath), "static")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if _, err := os.Stat("./static"); err == nil {
+		return "./static"
+	}
+	if _, err := os.Stat("./services/git-app-backend/static"); err == nil {
+		return "./services/git-app-backend/static"
+	}
+	return "./static"
+}
+
+// SPAHandler serves the Next.js static export as a single-page app,
+// falling back to index.html for client-side routes.
+func SPAHandler(staticPath string) http.HandlerFunc {
+	fileServer := http.FileServer(http.Dir(staticPath))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := staticPath + r.URL.Path
+		if info, err := os.Stat(path); err == nil {
+			if !info.IsDir() {
+				fileServer.ServeHTTP(w, r)
+				return
+			}
+			indexPath := path + "/index.html"
+			if _, err := os.Stat(indexPath); err == nil {
+				http.ServeFile(w, r, indexPath)
+				return
+			}
+		}
+		http.ServeFile(w, r, staticPath+"/index.html")
+	}
+}
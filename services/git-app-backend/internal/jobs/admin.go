@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git-app-backend/internal/auth"
+	"git-app-backend/internal/authz"
+	"git-app-backend/internal/httpx"
+)
+
+// DeliveryView is what /api/webhooks/deliveries returns: the raw payload
+// is deliberately omitted (it can contain repo contents and is usually
+// large) in favor of the fields an operator actually needs to triage a
+// stuck delivery.
+type DeliveryView struct {
+	ID             int64  `json:"id"`
+	DeliveryID     string `json:"delivery_id"`
+	EventType      string `json:"event_type"`
+	Owner          string `json:"owner"`
+	Repo           string `json:"repo"`
+	InstallationID int64  `json:"installation_id"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	MaxAttempts    int    `json:"max_attempts"`
+	LastError      string `json:"last_error,omitempty"`
+	NextRunAt      string `json:"next_run_at"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+const timeFormatRFC3339 = "2006-01-02T15:04:05Z07:00"
+
+// AdminHandler backs the dead-letter admin endpoints operators use to
+// see what's stuck and replay it once fixed. Both endpoints are scoped to
+// the caller's own installations - an installation_admins row is what
+// makes someone an "operator" for a given tenant, not just being logged
+// in at all.
+type AdminHandler struct {
+	DB *sql.DB
+
+	// InstallationIDsForUser resolves which installations a caller may
+	// administer; overridable in tests, defaults to
+	// authz.InstallationIDsForUser against DB when nil.
+	InstallationIDsForUser func(db *sql.DB, userID int64) ([]int64, error)
+
+	// IsInstallationAdmin checks a single installation_id, used by Replay
+	// once it has looked up the delivery's installation; overridable in
+	// tests, defaults to authz.IsInstallationAdmin against DB when nil.
+	IsInstallationAdmin func(db *sql.DB, userID, installationID int64) (bool, error)
+}
+
+func (h AdminHandler) installationIDsForUser(userID int64) ([]int64, error) {
+	resolve := h.InstallationIDsForUser
+	if resolve == nil {
+		resolve = authz.InstallationIDsForUser
+	}
+	return resolve(h.DB, userID)
+}
+
+func (h AdminHandler) isInstallationAdmin(userID, installationID int64) (bool, error) {
+	check := h.IsInstallationAdmin
+	if check == nil {
+		check = authz.IsInstallationAdmin
+	}
+	return check(h.DB, userID, installationID)
+}
+
+// List backs GET /api/webhooks/deliveries?status=failed.
+func (h AdminHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := auth.UserID(r.Context())
+	if !ok {
+		http.Error(w, "missing user", http.StatusUnauthorized)
+		return
+	}
+	installationIDs, err := h.installationIDsForUser(userID)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	deliveries, err := List(h.DB, r.URL.Query().Get("status"), installationIDs, limit)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]DeliveryView, 0, len(deliveries))
+	for _, d := range deliveries {
+		views = append(views, DeliveryView{
+			ID:             d.ID,
+			DeliveryID:     d.DeliveryID,
+			EventType:      d.EventType,
+			Owner:          d.Owner,
+			Repo:           d.Repo,
+			InstallationID: d.InstallationID,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			MaxAttempts:    d.MaxAttempts,
+			LastError:      d.LastError,
+			NextRunAt:      d.NextRunAt.Format(timeFormatRFC3339),
+			CreatedAt:      d.CreatedAt.Format(timeFormatRFC3339),
+			UpdatedAt:      d.UpdatedAt.Format(timeFormatRFC3339),
+		})
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"deliveries": views})
+}
+
+// Replay backs POST /api/webhooks/deliveries/{id}/replay, resetting a
+// dead-lettered delivery to queued so the worker pool picks it up again
+// on its next poll.
+func (h AdminHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	// ["api", "webhooks", "deliveries", "{id}", "replay"]
+	if len(parts) != 5 || parts[4] != "replay" {
+		http.Error(w, "invalid request path", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid delivery id", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := auth.UserID(r.Context())
+	if !ok {
+		http.Error(w, "missing user", http.StatusUnauthorized)
+		return
+	}
+	delivery, err := GetByID(h.DB, id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "delivery not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	allowed, err := h.isInstallationAdmin(userID, delivery.InstallationID)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "not authorized for this installation", http.StatusForbidden)
+		return
+	}
+
+	if err := Replay(h.DB, id); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{"replayed": true})
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git-app-backend/internal/auth"
+)
+
+func TestAdminHandlerList_RejectsUnauthenticated(t *testing.T) {
+	h := AdminHandler{
+		InstallationIDsForUser: func(db *sql.DB, userID int64) ([]int64, error) {
+			t.Fatal("InstallationIDsForUser should not be consulted when there is no authenticated user")
+			return nil, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries", nil)
+	rec := httptest.NewRecorder()
+	h.List(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with no authenticated user, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminHandlerList_EmptyScopeReturnsNoDeliveries(t *testing.T) {
+	h := AdminHandler{
+		InstallationIDsForUser: func(db *sql.DB, userID int64) ([]int64, error) {
+			return nil, nil // caller administers nothing
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries", nil)
+	req = req.WithContext(auth.WithUserID(req.Context(), 2))
+	rec := httptest.NewRecorder()
+	h.List(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != "{\"deliveries\":[]}\n" {
+		t.Fatalf("expected an empty deliveries list without touching the DB, got %s", rec.Body.String())
+	}
+}
+
+func TestAdminHandlerIsInstallationAdmin_UsesOverride(t *testing.T) {
+	h := AdminHandler{
+		IsInstallationAdmin: func(db *sql.DB, userID, installationID int64) (bool, error) {
+			return userID == 1 && installationID == 42, nil
+		},
+	}
+
+	allowed, err := h.isInstallationAdmin(1, 42)
+	if err != nil || !allowed {
+		t.Fatalf("expected admin of installation 42 to be allowed, got allowed=%v err=%v", allowed, err)
+	}
+
+	allowed, err = h.isInstallationAdmin(2, 42)
+	if err != nil || allowed {
+		t.Fatalf("expected non-admin to be denied, got allowed=%v err=%v", allowed, err)
+	}
+}
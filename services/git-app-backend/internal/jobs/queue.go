@@ -0,0 +1,320 @@
+// Package jobs is the persistent, idempotent webhook delivery queue:
+// every GitHub delivery is recorded before any work happens on it, and a
+// small worker pool leases and dispatches rows with exponential backoff
+// on failure.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// backoffSchedule is how long to wait before retrying a failed delivery,
+// indexed by attempts-so-far (capped at the last entry). This mirrors
+// the 30s/2m/10m/1h schedule GitHub itself uses for its own webhook
+// redelivery.
+var backoffSchedule = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	1 * time.Hour,
+}
+
+func backoff(attempts int) time.Duration {
+	if attempts < 0 {
+		attempts = 0
+	}
+	if attempts >= len(backoffSchedule) {
+		attempts = len(backoffSchedule) - 1
+	}
+	return backoffSchedule[attempts]
+}
+
+// DefaultMaxAttempts bounds retries before a delivery is left failed in
+// place for the dead-letter admin endpoint to surface, rather than
+// retried forever against a webhook that will never succeed (e.g. a
+// permanently revoked installation).
+const DefaultMaxAttempts = 8
+
+// Delivery is one recorded GitHub webhook delivery, tracked from receipt
+// through however many retries it takes to process successfully. The
+// delivery_id unique constraint is what makes the webhook ingress
+// handler idempotent under GitHub's at-least-once redelivery.
+type Delivery struct {
+	ID             int64
+	DeliveryID     string
+	EventType      string
+	Owner          string
+	Repo           string
+	InstallationID int64
+	Payload        []byte
+	Status         string // queued, running, succeeded, failed
+	Attempts       int
+	MaxAttempts    int
+	LastError      string
+	NextRunAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// EnsureQueueTables creates the webhook_deliveries table if it doesn't
+// already exist. Called once at startup alongside the DB connection.
+func EnsureQueueTables(db *sql.DB) error {
+	_, err := db.Exec(`
+		create table if not exists webhook_deliveries (
+			id bigserial primary key,
+			delivery_id text not null unique,
+			event_type text not null,
+			owner text not null default '',
+			repo text not null default '',
+			installation_id bigint not null default 0,
+			payload bytea not null,
+			status text not null default 'queued',
+			attempts integer not null default 0,
+			max_attempts integer not null default ` + fmt.Sprintf("%d", DefaultMaxAttempts) + `,
+			last_error text not null default '',
+			next_run_at timestamptz not null default now(),
+			created_at timestamptz not null default now(),
+			updated_at timestamptz not null default now()
+		);
+	`)
+	return err
+}
+
+// Enqueue records a newly received delivery. When deliveryID has already
+// been recorded (GitHub retried a delivery we already accepted), it does
+// nothing and returns enqueued=false so the caller can still answer 200
+// without doing any work twice.
+func Enqueue(db *sql.DB, deliveryID, eventType, owner, repo string, installationID int64, payload []byte) (enqueued bool, err error) {
+	res, err := db.Exec(`
+		insert into webhook_deliveries (delivery_id, event_type, owner, repo, installation_id, payload)
+		values ($1, $2, $3, $4, $5, $6)
+		on conflict (delivery_id) do nothing
+	`, deliveryID, eventType, owner, repo, installationID, payload)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// LeaseNext claims one due delivery (status queued, or failed and due
+// for retry) for processing, using SELECT ... FOR UPDATE SKIP LOCKED so
+// multiple worker goroutines (or replicas) can poll the same table
+// without leasing the same row twice.
+func LeaseNext(db *sql.DB) (*Delivery, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`
+		select id, delivery_id, event_type, owner, repo, installation_id, payload,
+		       status, attempts, max_attempts, last_error, next_run_at, created_at, updated_at
+		from webhook_deliveries
+		where status in ('queued', 'failed') and next_run_at <= now() and attempts < max_attempts
+		order by next_run_at
+		limit 1
+		for update skip locked
+	`)
+
+	delivery, err := scanDelivery(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`update webhook_deliveries set status = 'running', updated_at = now() where id = $1`, delivery.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	delivery.Status = "running"
+	return delivery, nil
+}
+
+func MarkSucceeded(db *sql.DB, id int64) error {
+	_, err := db.Exec(`
+		update webhook_deliveries set status = 'succeeded', last_error = '', updated_at = now() where id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed records the failure and, if attempts remain, reschedules
+// with exponential backoff; once attempts is exhausted the row stays
+// status='failed' permanently for the dead-letter endpoint.
+func MarkFailed(db *sql.DB, delivery *Delivery, cause error) error {
+	attempts := delivery.Attempts + 1
+	nextRunAt := time.Now().Add(backoff(attempts - 1))
+	_, err := db.Exec(`
+		update webhook_deliveries
+		set status = 'failed', attempts = $1, last_error = $2, next_run_at = $3, updated_at = now()
+		where id = $4
+	`, attempts, cause.Error(), nextRunAt, delivery.ID)
+	return err
+}
+
+// Replay resets a dead-lettered delivery for immediate reprocessing,
+// used by the admin replay endpoint after an operator has fixed whatever
+// made it fail (an AI Core outage, a revoked token, etc).
+func Replay(db *sql.DB, id int64) error {
+	_, err := db.Exec(`
+		update webhook_deliveries
+		set status = 'queued', attempts = 0, last_error = '', next_run_at = now(), updated_at = now()
+		where id = $1
+	`, id)
+	return err
+}
+
+// List returns deliveries belonging to one of installationIDs, optionally
+// narrowed by statusFilter. installationIDs is required and never
+// bypassed - an empty list returns no rows rather than every tenant's
+// deliveries, since this backs a caller-scoped admin endpoint.
+func List(db *sql.DB, statusFilter string, installationIDs []int64, limit int) ([]*Delivery, error) {
+	if len(installationIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		select id, delivery_id, event_type, owner, repo, installation_id, payload,
+		       status, attempts, max_attempts, last_error, next_run_at, created_at, updated_at
+		from webhook_deliveries
+		where installation_id = any($1)
+	`
+	args := []any{pq.Array(installationIDs)}
+	if statusFilter != "" {
+		query += " and status = $2"
+		args = append(args, statusFilter)
+	}
+	query += " order by created_at desc limit " + fmt.Sprintf("%d", limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetByID fetches a single delivery, so callers like the replay endpoint
+// can authorize against its installation_id before acting on it.
+func GetByID(db *sql.DB, id int64) (*Delivery, error) {
+	row := db.QueryRow(`
+		select id, delivery_id, event_type, owner, repo, installation_id, payload,
+		       status, attempts, max_attempts, last_error, next_run_at, created_at, updated_at
+		from webhook_deliveries where id = $1
+	`, id)
+	return scanDelivery(row)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	d := &Delivery{}
+	err := row.Scan(
+		&d.ID, &d.DeliveryID, &d.EventType, &d.Owner, &d.Repo, &d.InstallationID, &d.Payload,
+		&d.Status, &d.Attempts, &d.MaxAttempts, &d.LastError, &d.NextRunAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// HandlerFunc processes one delivery's payload. Returning an error
+// reschedules the delivery via MarkFailed instead of dropping it.
+type HandlerFunc func(ctx context.Context, delivery *Delivery) error
+
+// Dispatcher routes a delivery to the handler registered for its event
+// type, so new event types (check_run, installation, ...) can be
+// supported by registering a handler rather than editing a switch deep
+// in the worker loop.
+type Dispatcher struct {
+	handlers map[string]HandlerFunc
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]HandlerFunc)}
+}
+
+func (d *Dispatcher) Register(eventType string, fn HandlerFunc) {
+	d.handlers[eventType] = fn
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, delivery *Delivery) error {
+	fn, ok := d.handlers[delivery.EventType]
+	if !ok {
+		// No handler registered for this event type: nothing to do, and
+		// not an error worth retrying over.
+		return nil
+	}
+	return fn(ctx, delivery)
+}
+
+// StartWorkers launches concurrency worker goroutines, each polling
+// LeaseNext in a loop.
+func StartWorkers(ctx context.Context, db *sql.DB, dispatcher *Dispatcher, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	for i := 0; i < concurrency; i++ {
+		go runWorker(ctx, db, dispatcher)
+	}
+}
+
+func runWorker(ctx context.Context, db *sql.DB, dispatcher *Dispatcher) {
+	const idlePoll = 2 * time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		delivery, err := LeaseNext(db)
+		if err != nil {
+			log.Printf("webhook worker: lease failed: %v", err)
+			time.Sleep(idlePoll)
+			continue
+		}
+		if delivery == nil {
+			time.Sleep(idlePoll)
+			continue
+		}
+
+		if err := dispatcher.Dispatch(ctx, delivery); err != nil {
+			log.Printf("webhook worker: delivery %s (%s) failed attempt %d: %v", delivery.DeliveryID, delivery.EventType, delivery.Attempts+1, err)
+			if markErr := MarkFailed(db, delivery, err); markErr != nil {
+				log.Printf("webhook worker: failed to record failure for delivery %s: %v", delivery.DeliveryID, markErr)
+			}
+			continue
+		}
+		if err := MarkSucceeded(db, delivery.ID); err != nil {
+			log.Printf("webhook worker: failed to record success for delivery %s: %v", delivery.DeliveryID, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: -1, want: 30 * time.Second},
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: 2 * time.Minute},
+		{attempts: 2, want: 10 * time.Minute},
+		{attempts: 3, want: 1 * time.Hour},
+		{attempts: 50, want: 1 * time.Hour}, // caps at the last schedule entry
+	}
+	for _, c := range cases {
+		if got := backoff(c.attempts); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempts, got, c.want)
+		}
+	}
+}
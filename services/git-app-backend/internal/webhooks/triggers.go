@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"path"
+
+	"git-app-backend/internal/config"
+)
+
+// matchTrigger finds the first enabled [[trigger]] whose owner/repo glob,
+// branch list, and changed-path globs all match. When no triggers are
+// configured at all, it falls back to the pre-config-file hardcoded
+// behavior (default branch only) so an installation that hasn't written
+// a config file sees no change in what fires.
+func (h Handler) matchTrigger(owner, repo, branch string, changedPaths []string) (config.TriggerConfig, bool) {
+	cfg := h.Config.Get()
+	if len(cfg.Triggers) == 0 {
+		if branch == "main" {
+			return config.TriggerConfig{Branches: []string{"main"}, Agent: cfg.Agents.DefaultRunner, Enabled: true}, true
+		}
+		return config.TriggerConfig{}, false
+	}
+
+	fullName := owner + "/" + repo
+	for _, t := range cfg.Triggers {
+		if !t.Enabled {
+			continue
+		}
+		if t.Match != "" {
+			if matched, _ := path.Match(t.Match, fullName); !matched {
+				continue
+			}
+		}
+		if !branchMatches(t.Branches, branch) {
+			continue
+		}
+		if !pathsMatch(t.Paths, changedPaths) {
+			continue
+		}
+		return t, true
+	}
+	return config.TriggerConfig{}, false
+}
+
+func branchMatches(branches []string, branch string) bool {
+	if len(branches) == 0 {
+		return true
+	}
+	for _, b := range branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// pathsMatch reports whether any changed path matches any configured
+// glob. An empty glob list means "don't filter by path"; an empty
+// changed-path list means the event carried nothing to check against
+// (e.g. a pull_request event, which doesn't include a file list) - both
+// cases pass through rather than blocking the trigger.
+func pathsMatch(globs, changedPaths []string) bool {
+	if len(globs) == 0 || len(changedPaths) == 0 {
+		return true
+	}
+	for _, g := range globs {
+		for _, p := range changedPaths {
+			if matched, _ := path.Match(g, p); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"testing"
+
+	"git-app-backend/internal/config"
+)
+
+func TestMatchTrigger_NoTriggersFallsBackToMainOnly(t *testing.T) {
+	h := Handler{Config: config.NewStore(config.Config{})}
+
+	if _, ok := h.matchTrigger("acme", "widgets", "main", nil); !ok {
+		t.Fatal("expected the no-triggers-configured fallback to match main")
+	}
+	if _, ok := h.matchTrigger("acme", "widgets", "feature-x", nil); ok {
+		t.Fatal("expected the no-triggers-configured fallback to reject a non-main branch")
+	}
+}
+
+func TestMatchTrigger_MatchesOwnerRepoGlobBranchAndPath(t *testing.T) {
+	cfg := config.Config{
+		Triggers: []config.TriggerConfig{
+			{Match: "acme/*", Branches: []string{"main", "release"}, Paths: []string{"src/**"}, Agent: "linter", Enabled: true},
+		},
+	}
+	h := Handler{Config: config.NewStore(cfg)}
+
+	trigger, ok := h.matchTrigger("acme", "widgets", "release", []string{"src/app.go"})
+	if !ok {
+		t.Fatal("expected trigger to match")
+	}
+	if trigger.Agent != "linter" {
+		t.Fatalf("expected matched trigger's agent to be %q, got %q", "linter", trigger.Agent)
+	}
+
+	if _, ok := h.matchTrigger("other-org", "widgets", "release", []string{"src/app.go"}); ok {
+		t.Fatal("expected owner/repo glob mismatch to reject")
+	}
+	if _, ok := h.matchTrigger("acme", "widgets", "dev", []string{"src/app.go"}); ok {
+		t.Fatal("expected branch mismatch to reject")
+	}
+}
+
+func TestMatchTrigger_DisabledTriggerIsSkipped(t *testing.T) {
+	cfg := config.Config{
+		Triggers: []config.TriggerConfig{
+			{Match: "acme/*", Enabled: false},
+		},
+	}
+	h := Handler{Config: config.NewStore(cfg)}
+
+	if _, ok := h.matchTrigger("acme", "widgets", "main", nil); ok {
+		t.Fatal("expected a disabled trigger to never match")
+	}
+}
+
+func TestPathsMatch_EmptyListsPassThrough(t *testing.T) {
+	if !pathsMatch(nil, []string{"src/app.go"}) {
+		t.Fatal("expected no configured globs to pass through")
+	}
+	if !pathsMatch([]string{"src/**"}, nil) {
+		t.Fatal("expected no changed paths (e.g. a pull_request event) to pass through")
+	}
+	if pathsMatch([]string{"docs/**"}, []string{"src/app.go"}) {
+		t.Fatal("expected a non-matching glob to reject")
+	}
+}
@@ -0,0 +1,265 @@
+// Package webhooks is the GitHub webhook ingress and dispatch layer: it
+// records every delivery before doing any work with it, then routes
+// leased deliveries to per-event handlers that resolve an agent.Runner,
+// run it, and publish the result as a Check Run.
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+
+	"git-app-backend/internal/agent"
+	"git-app-backend/internal/auth"
+	"git-app-backend/internal/authz"
+	"git-app-backend/internal/checks"
+	"git-app-backend/internal/config"
+	"git-app-backend/internal/githubx"
+	"git-app-backend/internal/jobs"
+)
+
+// Handler wires together the DB, live config, and GitHub client provider
+// needed to ingress and dispatch webhook deliveries.
+type Handler struct {
+	DB      *sql.DB
+	Config  *config.Store
+	Clients githubx.ClientProvider
+}
+
+// Ingress records every delivery before doing any work with it: the
+// delivery_id unique constraint makes GitHub's at-least-once redelivery
+// idempotent (a retried delivery gets a 200 immediately without being
+// processed twice), and persisting first means a deploy or crash
+// mid-processing loses nothing - the worker pool started in main picks
+// queued/failed rows back up from the jobs queue instead of a bare
+// goroutine's in-flight work vanishing with the process.
+func (h Handler) Ingress(w http.ResponseWriter, r *http.Request) {
+	payload, err := github.ValidatePayload(r, []byte(h.Config.Get().GitHub.WebhookSecret))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusUnauthorized)
+		return
+	}
+
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	if deliveryID == "" {
+		http.Error(w, "missing X-GitHub-Delivery header", http.StatusBadRequest)
+		return
+	}
+	eventType := github.WebHookType(r)
+
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	var owner, repo string
+	var installationID int64
+	switch e := event.(type) {
+	case *github.PushEvent:
+		owner = e.GetRepo().GetOwner().GetLogin()
+		repo = e.GetRepo().GetName()
+		installationID = e.GetInstallation().GetID()
+	case *github.PullRequestEvent:
+		owner = e.GetRepo().GetOwner().GetLogin()
+		repo = e.GetRepo().GetName()
+		installationID = e.GetInstallation().GetID()
+	case *github.CheckRunEvent:
+		if e.GetAction() != "rerequested" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		owner = e.GetRepo().GetOwner().GetLogin()
+		repo = e.GetRepo().GetName()
+		installationID = e.GetInstallation().GetID()
+	case *github.InstallationEvent:
+		// Not repo-scoped and not worth a queue row: grant synchronously
+		// instead of enqueueing. A failure (including the installer not
+		// having logged in here yet) returns 500 rather than acking, so
+		// GitHub's own redelivery is what gives them another chance at
+		// the grant - a silent 204 here would drop it for good.
+		if err := h.handleInstallationEvent(e); err != nil {
+			fmt.Printf("failed to handle installation event for delivery %s: %v\n", deliveryID, err)
+			http.Error(w, "failed to process installation event", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		// Not an event type we dispatch on, but still ack it so GitHub
+		// doesn't treat it as a failed delivery and retry forever.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, err := jobs.Enqueue(h.DB, deliveryID, eventType, owner, repo, installationID, payload); err != nil {
+		fmt.Printf("failed to enqueue webhook delivery %s: %v\n", deliveryID, err)
+		http.Error(w, "failed to record delivery", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleInstallationEvent grants the installing GitHub user admin rights
+// over the new installation - the "installation" webhook's sender is the
+// only point in this flow where we know which platform user authorized
+// it, so this is where internal/authz's installation_admins table gets
+// populated. It returns an error when that GitHub user hasn't logged in
+// here yet (no users row to resolve to) rather than swallowing it, so
+// Ingress can 500 and GitHub's own redelivery gives them another chance
+// once they've logged in and reinstalled.
+func (h Handler) handleInstallationEvent(e *github.InstallationEvent) error {
+	if e.GetAction() != "created" {
+		return nil
+	}
+	installationID := e.GetInstallation().GetID()
+	userID, err := auth.UserIDForGitHubID(h.DB, e.GetSender().GetID())
+	if err != nil {
+		return fmt.Errorf("installation %d created by GitHub user %d, who hasn't logged in here yet: %w", installationID, e.GetSender().GetID(), err)
+	}
+	if err := authz.GrantInstallationAdmin(h.DB, userID, installationID); err != nil {
+		return fmt.Errorf("grant installation admin for installation %d: %w", installationID, err)
+	}
+	return nil
+}
+
+// Dispatcher wires event types to their handler so the jobs worker pool
+// knows what to run for each leased delivery. Push only triggers the
+// agent on the default branch; PR review only runs on the actions that
+// actually changed the diff.
+func (h Handler) Dispatcher() *jobs.Dispatcher {
+	d := jobs.NewDispatcher()
+	d.Register("push", h.dispatchPush)
+	d.Register("pull_request", h.dispatchPullRequest)
+	d.Register("check_run", h.dispatchCheckRun)
+	return d
+}
+
+func (h Handler) dispatchPush(ctx context.Context, delivery *jobs.Delivery) error {
+	var e github.PushEvent
+	if err := json.Unmarshal(delivery.Payload, &e); err != nil {
+		return fmt.Errorf("unmarshal push event: %w", err)
+	}
+	branch := strings.TrimPrefix(e.GetRef(), "refs/heads/")
+	trigger, ok := h.matchTrigger(delivery.Owner, delivery.Repo, branch, changedPathsFromPush(&e))
+	if !ok {
+		return nil
+	}
+	return h.runAgentForRef(ctx, "push", delivery.Owner, delivery.Repo, delivery.InstallationID, e.GetAfter(), nil, trigger.Agent)
+}
+
+func changedPathsFromPush(e *github.PushEvent) []string {
+	var paths []string
+	for _, c := range e.Commits {
+		paths = append(paths, c.Added...)
+		paths = append(paths, c.Removed...)
+		paths = append(paths, c.Modified...)
+	}
+	return paths
+}
+
+func (h Handler) dispatchPullRequest(ctx context.Context, delivery *jobs.Delivery) error {
+	var e github.PullRequestEvent
+	if err := json.Unmarshal(delivery.Payload, &e); err != nil {
+		return fmt.Errorf("unmarshal pull_request event: %w", err)
+	}
+	action := e.GetAction()
+	if action != "opened" && action != "synchronize" {
+		return nil
+	}
+	baseBranch := e.GetPullRequest().GetBase().GetRef()
+	trigger, ok := h.matchTrigger(delivery.Owner, delivery.Repo, baseBranch, nil)
+	if !ok {
+		return nil
+	}
+	return h.runAgentForRef(ctx, "pull_request", delivery.Owner, delivery.Repo, delivery.InstallationID, e.GetPullRequest().GetHead().GetSHA(), e.Number, trigger.Agent)
+}
+
+// dispatchCheckRun re-enqueues the agent when a user clicks "Re-run" on
+// a Check Run from the Checks tab. Whether the rerun targets a PR or a
+// bare push is inferred from whether GitHub attached any pull requests
+// to the check_run payload.
+func (h Handler) dispatchCheckRun(ctx context.Context, delivery *jobs.Delivery) error {
+	var e github.CheckRunEvent
+	if err := json.Unmarshal(delivery.Payload, &e); err != nil {
+		return fmt.Errorf("unmarshal check_run event: %w", err)
+	}
+	if e.GetAction() != "rerequested" {
+		return nil
+	}
+
+	headSHA := e.GetCheckRun().GetHeadSHA()
+	eventType := "push"
+	var prNumber *int
+	if prs := e.GetCheckRun().PullRequests; len(prs) > 0 {
+		n := prs[0].GetNumber()
+		prNumber = &n
+		eventType = "pull_request"
+	}
+
+	return h.runAgentForRef(ctx, eventType, delivery.Owner, delivery.Repo, delivery.InstallationID, headSHA, prNumber, "")
+}
+
+// runAgentForRef resolves the agent.Runner configured for this
+// installation/eventType - agent.DBConfig first, falling back to
+// triggerAgent (the matched [[trigger]]'s agent, itself overridable by
+// the repo's own .testpilot.yml) - runs it against an agent.RepoContext
+// for headSHA, and publishes the result as a Check Run. For push events
+// (prNumber == nil, no PR to attach a check's inline review to yet) the
+// result is also landed on a new branch and opened as a PR.
+func (h Handler) runAgentForRef(ctx context.Context, eventType, owner, repo string, installationID int64, headSHA string, prNumber *int, triggerAgent string) error {
+	client, err := h.Clients.NewInstallationClient(installationID)
+	if err != nil {
+		return err
+	}
+
+	if override, err := githubx.LoadRepoOverride(ctx, client, owner, repo, headSHA); err != nil {
+		fmt.Printf("failed to load .testpilot.yml for %s/%s@%s: %v\n", owner, repo, headSHA, err)
+	} else if override != nil {
+		if override.Enabled != nil && !*override.Enabled {
+			return nil
+		}
+		if override.Agent != "" {
+			triggerAgent = override.Agent
+		}
+	}
+
+	fallback := agent.ResolveNamed(triggerAgent, h.Config.Get().Agents.Runners)
+	runner, err := agent.ResolveRunner(h.DB, installationID, eventType, fallback)
+	if err != nil {
+		return fmt.Errorf("resolve agent runner: %w", err)
+	}
+
+	publisher := checks.New(client)
+	checkRunID, err := publisher.Start(ctx, owner, repo, headSHA)
+	if err != nil {
+		return fmt.Errorf("start check run: %w", err)
+	}
+
+	result, runErr := runner.Run(ctx, agent.RepoContext{
+		Client:         client,
+		Owner:          owner,
+		Repo:           repo,
+		InstallationID: installationID,
+		BaseSHA:        headSHA,
+	})
+	if runErr != nil {
+		_ = publisher.Finish(ctx, owner, repo, checkRunID, "failure", fmt.Sprintf("Agent run failed: %v", runErr), nil)
+		return fmt.Errorf("agent run: %w", runErr)
+	}
+
+	if err := publisher.Finish(ctx, owner, repo, checkRunID, checks.ConclusionFor(result.Annotations), result.PRBody, result.Annotations); err != nil {
+		return fmt.Errorf("finish check run: %w", err)
+	}
+
+	if prNumber != nil {
+		return nil
+	}
+	return agent.ApplyResult(ctx, client, owner, repo, result)
+}
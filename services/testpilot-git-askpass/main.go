@@ -0,0 +1,95 @@
+// Command testpilot-git-askpass is the GIT_ASKPASS helper the VS Code
+// extension registers before shelling out to git. Git invokes it as
+// `testpilot-git-askpass "Username for 'https://github.com': "` (or
+// "Password for ..."); this prints only the credential value, never the
+// prompt, since that's what GIT_ASKPASS contracts expect on stdout.
+//
+// Configuration travels through environment variables rather than flags,
+// since git controls the argv this binary is invoked with:
+//
+//	TESTPILOT_GATEWAY_URL   base URL of the gateway's askpass endpoint
+//	TESTPILOT_USER_JWT      the IDE session's user_jwt
+//	TESTPILOT_GIT_HOST      host git is talking to (e.g. "github.com")
+//	TESTPILOT_GIT_PATH      repo path git is talking to (e.g. "owner/repo.git")
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type askpassRequest struct {
+	UserJWT string `json:"user_jwt"`
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+}
+
+type askpassResponse struct {
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "testpilot-git-askpass: expected a git prompt argument")
+		os.Exit(1)
+	}
+	prompt := strings.ToLower(os.Args[1])
+
+	gatewayURL := os.Getenv("TESTPILOT_GATEWAY_URL")
+	if gatewayURL == "" {
+		fmt.Fprintln(os.Stderr, "testpilot-git-askpass: TESTPILOT_GATEWAY_URL not set")
+		os.Exit(1)
+	}
+
+	cred, err := fetchCredential(gatewayURL, askpassRequest{
+		UserJWT: os.Getenv("TESTPILOT_USER_JWT"),
+		Host:    os.Getenv("TESTPILOT_GIT_HOST"),
+		Path:    os.Getenv("TESTPILOT_GIT_PATH"),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "testpilot-git-askpass: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		fmt.Println(cred.Username)
+	case strings.HasPrefix(prompt, "password"):
+		fmt.Println(cred.Password)
+	default:
+		fmt.Fprintf(os.Stderr, "testpilot-git-askpass: unrecognized prompt %q\n", os.Args[1])
+		os.Exit(1)
+	}
+}
+
+func fetchCredential(gatewayURL string, req askpassRequest) (*askpassResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(strings.TrimRight(gatewayURL, "/")+"/api/v1/git/askpass", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gateway unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gateway returned status %d", resp.StatusCode)
+	}
+
+	var out askpassResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid response from gateway: %w", err)
+	}
+	return &out, nil
+}